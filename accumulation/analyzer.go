@@ -18,13 +18,25 @@
 package accumulation
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/types"
+	"os"
+	"path/filepath"
 	"reflect"
 	"runtime/debug"
+	"strings"
+	"time"
 
 	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/assertion"
+	"go.uber.org/nilaway/assertion/affiliation"
+	"go.uber.org/nilaway/assertion/function"
 	"go.uber.org/nilaway/assertion/function/assertiontree"
+	"go.uber.org/nilaway/assertion/function/functioncontracts"
+	"go.uber.org/nilaway/assertion/global"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/diagnostic"
 	"go.uber.org/nilaway/inference"
@@ -45,8 +57,16 @@ var Analyzer = &analysis.Analyzer{
 	FactTypes: []analysis.Fact{
 		new(inference.InferredMap),
 	},
-	Requires:   []*analysis.Analyzer{config.Analyzer, assertion.Analyzer, annotation.Analyzer},
-	ResultType: reflect.TypeOf(([]analysis.Diagnostic)(nil)),
+	Requires: []*analysis.Analyzer{
+		config.Analyzer,
+		assertion.Analyzer,
+		annotation.Analyzer,
+		functioncontracts.Analyzer,
+		affiliation.Analyzer,
+		function.Analyzer,
+		global.Analyzer,
+	},
+	ResultType: reflect.TypeOf(([]diagnostic.Diagnostic)(nil)),
 }
 
 // run is the primary driver function for NilAway's analysis.
@@ -82,19 +102,23 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 			// Deferred functions are executed after a result is generated, so here we modify the
 			// return value `result` in-place.
 			// Diagnostics with invalid positions (<= 0) will be silently suppressed, so here we use 1.
-			d := analysis.Diagnostic{Pos: 1, Message: fmt.Sprintf("INTERNAL PANIC: %s\n%s", r, string(debug.Stack()))}
-			if diagnostics, ok := result.([]analysis.Diagnostic); ok {
+			d := diagnostic.Diagnostic{Diagnostic: analysis.Diagnostic{
+				Pos:     1,
+				Message: fmt.Sprintf("INTERNAL PANIC: %s\n%s", r, string(debug.Stack())),
+			}}
+			if diagnostics, ok := result.([]diagnostic.Diagnostic); ok {
 				result = append(diagnostics, d)
 			} else {
-				result = []analysis.Diagnostic{d}
+				result = []diagnostic.Diagnostic{d}
 			}
 		}
 	}()
 
+	start := time.Now()
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 	if !conf.IsPkgInScope(pass.Pkg) {
 		// Must return a typed nil since the driver is using reflection to retrieve the result.
-		return ([]analysis.Diagnostic)(nil), nil
+		return ([]diagnostic.Diagnostic)(nil), nil
 	}
 
 	assertionsResult := pass.ResultOf[assertion.Analyzer].(assertion.Result)
@@ -122,12 +146,18 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 	mode := inference.DetermineMode(pass)
 
 	// First observe all annotations from annotationsResult (observes only syntactic annotations
-	// for FullInfer mode, otherwise all annotations for NoInfer)
-	inferenceEngine.ObserveAnnotations(annotationsResult.AnnotationMap, mode)
+	// for FullInfer mode, otherwise all annotations for NoInfer). In annotate mode, the
+	// hand-written annotations of the current package are not observed such that the inferred
+	// ones can be compared against them.
+	if conf.Annotate {
+		inferenceEngine.ObserveNonLocalAnnotations(annotationsResult.AnnotationMap, mode)
+	} else {
+		inferenceEngine.ObserveAnnotations(annotationsResult.AnnotationMap, mode)
+	}
 
 	var (
 		inferredMap *inference.InferredMap
-		diagnostics []analysis.Diagnostic
+		diagnostics []diagnostic.Diagnostic
 	)
 	switch mode {
 	case inference.FullInfer:
@@ -136,11 +166,22 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		// sites unless we really have a reason they have to be determined.
 		inferenceEngine.ObservePackage(assertionsResult.FullTriggers)
 		inferredMap = inferenceEngine.InferredMap()
+		if conf.Annotate {
+			// Report the inferred annotations instead of the errors.
+			for _, d := range annotation.Annotate(pass, annotationsResult.AnnotationMap, inferredMap) {
+				diagnostics = append(diagnostics, diagnostic.Diagnostic{Diagnostic: d})
+			}
+			break
+		}
 		diagnostics = diagnosticEngine.Diagnostics(true /* grouping */)
 
 	case inference.NoInfer:
 		// In non-inference case - use the classical assertionNode.CheckErrors method to determine error outputs
 		inferredMap = inferenceEngine.InferredMap()
+		if conf.Annotate {
+			// Nothing is inferred without inference.
+			break
+		}
 		checkErrors(assertionsResult.FullTriggers, inferredMap, diagnosticEngine)
 		// Retrieve the diagnostics from the engine. Note that we should not group the
 		// diagnostics for easier unit testing.
@@ -150,6 +191,41 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		panic("Invalid mode for running NilAway")
 	}
 
+	// Report the malformed or invalid function contracts, which are otherwise silently dropped,
+	// and the interface implementations not satisfying the contracts of the interface methods.
+	for _, ds := range [...][]analysis.Diagnostic{
+		pass.ResultOf[functioncontracts.Analyzer].(functioncontracts.Result).Diagnostics,
+		pass.ResultOf[affiliation.Analyzer].(affiliation.Result).Diagnostics,
+	} {
+		for _, d := range ds {
+			diagnostics = append(diagnostics, diagnostic.Diagnostic{Diagnostic: d})
+		}
+	}
+
+	// Report the `//nilaway:ignore` directives that did not suppress any conflict, if requested.
+	if conf.ReportUnusedIgnores && !conf.Annotate {
+		diagnostics = append(diagnostics, diagnosticEngine.UnusedSuppressions()...)
+	}
+
+	// Report the functions that are not analyzed for exceeding the size limits, if requested.
+	if conf.ReportSkippedFuncs && !conf.Annotate {
+		diagnostics = append(diagnostics, skippedFuncsToDiagnostics(pass, assertionsResult.SkippedFuncs)...)
+	}
+
+	// Always report the functions abandoned for exceeding the analysis budget, since the errors
+	// in them are silently missed otherwise.
+	diagnostics = append(diagnostics, incompleteFuncsToDiagnostics(assertionsResult.IncompleteFuncs)...)
+
+	// In explain mode, report the explanation of the queried annotation site instead of errors.
+	if query := conf.ExplainSite(); query != "" {
+		diagnostics = explainSite(pass, inferredMap, query)
+	}
+
+	// Dump the implication graph for debugging, if requested.
+	if dir := conf.ImplicationGraphDir(); dir != "" {
+		diagnostics = append(diagnostics, dumpImplicationGraph(pass, inferredMap, dir, conf.ImplicationGraphFunc())...)
+	}
+
 	// Export the _incremental_ information from this inferred map for analysis of downstream
 	// packages via the Fact mechanism (which [uses gob encoding under the hood]). The custom
 	// GobEncode / GobDecode methods of InferredAnnotationMap ensure that only incremental
@@ -159,21 +235,161 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 	//
 	// [uses gob encoding under the hood]: https://pkg.go.dev/golang.org/x/tools/go/analysis#hdr-Modular_analysis_with_Facts
 	// [gob encoding]: https://pkg.go.dev/encoding/gob#hdr-Basics
-	inferredMap.Export(pass)
+	exported := inferredMap.Export(pass)
+
+	// Write the performance statistics of the package, if requested.
+	if dir := conf.StatsDir(); dir != "" {
+		diagnostics = append(diagnostics, writeStats(pass, dir, time.Since(start), inferredMap, exported)...)
+	}
 
 	return diagnostics, nil
 }
 
 // errorsToDiagnostics converts the internal errors to a slice of analysis.Diagnostic to be reported.
-func errorsToDiagnostics(errs []error) []analysis.Diagnostic {
-	diagnostics := make([]analysis.Diagnostic, len(errs))
+func errorsToDiagnostics(errs []error) []diagnostic.Diagnostic {
+	diagnostics := make([]diagnostic.Diagnostic, len(errs))
 	for i, err := range errs {
 		// Diagnostics with invalid positions (<= 0) will be silently suppressed, so here we use 1.
-		diagnostics[i] = analysis.Diagnostic{Pos: 1, Message: "INTERNAL ERROR: " + err.Error()}
+		diagnostics[i] = diagnostic.Diagnostic{Diagnostic: analysis.Diagnostic{Pos: 1, Message: "INTERNAL ERROR: " + err.Error()}}
+	}
+	return diagnostics
+}
+
+// skippedFuncsToDiagnostics converts the functions skipped by the assertion analysis (for exceeding
+// the size limits) to diagnostics reporting their sizes and the limits.
+func skippedFuncsToDiagnostics(pass *analysis.Pass, skipped []function.SkippedFunc) []diagnostic.Diagnostic {
+	conf := pass.ResultOf[config.Analyzer].(*config.Config)
+	diagnostics := make([]diagnostic.Diagnostic, len(skipped))
+	for i, f := range skipped {
+		diagnostics[i] = diagnostic.Diagnostic{Diagnostic: analysis.Diagnostic{
+			Pos: f.Pos,
+			Message: fmt.Sprintf("Skipped analyzing `%s` (%d bytes, %d CFG blocks) for exceeding the size limits (%s=%d, %s=%d)",
+				f.Name, f.SizeInBytes, f.NumBlocks, config.MaxFuncSizeFlag, conf.MaxFuncSizeInBytes(),
+				config.MaxFuncBlocksFlag, conf.MaxFuncBlocks()),
+		}}
+	}
+	return diagnostics
+}
+
+// IncompleteAnalysisCategory is the category of the diagnostics reporting the functions abandoned
+// during the analysis for exceeding the analysis budget, such that they can be told apart from
+// the nil flow errors by the drivers.
+const IncompleteAnalysisCategory = "analysis-incomplete"
+
+// incompleteFuncsToDiagnostics converts the functions abandoned by the assertion analysis (for
+// exceeding the analysis budget) to diagnostics in IncompleteAnalysisCategory.
+func incompleteFuncsToDiagnostics(incomplete []function.IncompleteFunc) []diagnostic.Diagnostic {
+	diagnostics := make([]diagnostic.Diagnostic, len(incomplete))
+	for i, f := range incomplete {
+		diagnostics[i] = diagnostic.Diagnostic{Diagnostic: analysis.Diagnostic{
+			Pos:      f.Pos,
+			Category: IncompleteAnalysisCategory,
+			Message:  fmt.Sprintf("Analysis incomplete for `%s`: %s, potential nil panics in it are not reported", f.Name, f.Reason),
+		}}
 	}
 	return diagnostics
 }
 
+// explainSite returns a diagnostic at the declaration of the annotation site named by the query
+// (see annotation.LookupSite), explaining why the site is inferred nilable or nonnil. Nothing is
+// returned if the site is not declared in the current package.
+func explainSite(pass *analysis.Pass, inferredMap *inference.InferredMap, query string) []diagnostic.Diagnostic {
+	name, _, _ := strings.Cut(query, ":")
+	if pkgPath, _ := annotation.SplitSiteQuery(name); pkgPath != pass.Pkg.Path() || len(pass.Files) == 0 {
+		return nil
+	}
+	key, obj, err := annotation.LookupSite(pass.Pkg, query)
+	if err != nil {
+		return []diagnostic.Diagnostic{{Diagnostic: analysis.Diagnostic{
+			Pos:     pass.Files[0].Package,
+			Message: fmt.Sprintf("Cannot explain %q: %v", query, err),
+		}}}
+	}
+
+	message := inferredMap.Explain(key, false /* isDeep */)
+	switch obj.Type().Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan:
+		message += "\n" + inferredMap.Explain(key, true /* isDeep */)
+	}
+	return []diagnostic.Diagnostic{{Diagnostic: analysis.Diagnostic{Pos: obj.Pos(), Message: message}}}
+}
+
+// dumpImplicationGraph writes the implication graph of the current package (or of the function
+// named by funcName, if not empty) to "<dir>/<package path>.dot" and "<dir>/<package path>.json",
+// and returns a diagnostic if it fails to do so. Nothing is written if the function is not
+// declared in the current package.
+func dumpImplicationGraph(pass *analysis.Pass, inferredMap *inference.InferredMap, dir string, funcName string) []diagnostic.Diagnostic {
+	if len(pass.Files) == 0 {
+		return nil
+	}
+	report := func(err error) []diagnostic.Diagnostic {
+		return []diagnostic.Diagnostic{{Diagnostic: analysis.Diagnostic{
+			Pos:     pass.Files[0].Package,
+			Message: fmt.Sprintf("Cannot dump implication graph: %v", err),
+		}}}
+	}
+
+	var fn *ast.FuncDecl
+	if funcName != "" {
+		if pkgPath, _ := annotation.SplitSiteQuery(funcName); pkgPath != pass.Pkg.Path() {
+			return nil
+		}
+		if fn = lookupFuncDecl(pass, funcName); fn == nil {
+			return report(fmt.Errorf("no function named %q", funcName))
+		}
+	}
+	graph := inferredMap.ImplicationGraph(fn)
+
+	path := filepath.Join(dir, filepath.FromSlash(pass.Pkg.Path()))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return report(err)
+	}
+	var dot bytes.Buffer
+	if err := graph.WriteDOT(&dot); err != nil {
+		return report(err)
+	}
+	if err := os.WriteFile(path+".dot", dot.Bytes(), 0o644); err != nil {
+		return report(err)
+	}
+	content, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return report(err)
+	}
+	if err := os.WriteFile(path+".json", content, 0o644); err != nil {
+		return report(err)
+	}
+	return nil
+}
+
+// lookupFuncDecl returns the declaration of the function named as "<pkg path>.<func>" or
+// "<pkg path>.<type>.<method>" in the current package, or nil if there is none.
+func lookupFuncDecl(pass *analysis.Pass, funcName string) *ast.FuncDecl {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			name := fn.Name.Name
+			if fn.Recv != nil && len(fn.Recv.List) > 0 {
+				t := pass.TypesInfo.TypeOf(fn.Recv.List[0].Type)
+				if ptr, ok := t.(*types.Pointer); ok {
+					t = ptr.Elem()
+				}
+				named, ok := t.(*types.Named)
+				if !ok {
+					continue
+				}
+				name = named.Obj().Name() + "." + name
+			}
+			if pass.Pkg.Path()+"."+name == funcName {
+				return fn
+			}
+		}
+	}
+	return nil
+}
+
 type conflictHandler interface {
 	AddSingleAssertionConflict(trigger annotation.FullTrigger)
 }
@@ -0,0 +1,111 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulation
+
+import (
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion"
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/diagnostic"
+	"go.uber.org/nilaway/inference"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// _numShuffles is the number of times the full triggers are shuffled in
+// TestOrderIndependentInference.
+const _numShuffles = 10
+
+// _shuffleAnalyzer runs the inference with the full triggers of the package observed in different
+// random orders, and reports the diagnostics if they are identical across all runs (or an error
+// otherwise).
+var _shuffleAnalyzer = &analysis.Analyzer{
+	Name:       "nilaway_shuffle_analyzer",
+	Doc:        "Run the inference with the full triggers observed in random orders",
+	Run:        runShuffled,
+	FactTypes:  []analysis.Fact{new(inference.InferredMap)},
+	Requires:   []*analysis.Analyzer{config.Analyzer, assertion.Analyzer, annotation.Analyzer},
+	ResultType: reflect.TypeOf(([]diagnostic.Diagnostic)(nil)),
+}
+
+func runShuffled(pass *analysis.Pass) (interface{}, error) {
+	triggers := pass.ResultOf[assertion.Analyzer].(assertion.Result).FullTriggers
+	annotations := pass.ResultOf[annotation.Analyzer].(annotation.Result).AnnotationMap
+
+	var first []diagnostic.Diagnostic
+	for seed := int64(0); seed < _numShuffles; seed++ {
+		shuffled := make([]annotation.FullTrigger, len(triggers))
+		copy(shuffled, triggers)
+		r := rand.New(rand.NewSource(seed))
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		diagnosticEngine := diagnostic.NewEngine(pass)
+		inferenceEngine := inference.NewEngine(pass, diagnosticEngine)
+		inferenceEngine.ObserveUpstream()
+		inferenceEngine.ObserveAnnotations(annotations, inference.FullInfer)
+		inferenceEngine.ObservePackage(shuffled)
+		diagnostics := diagnosticEngine.Diagnostics(true /* grouping */)
+
+		if seed == 0 {
+			first = diagnostics
+			continue
+		}
+		if len(diagnostics) != len(first) {
+			return nil, fmt.Errorf("shuffle %d: got %d diagnostics, want %d", seed, len(diagnostics), len(first))
+		}
+		for i, d := range diagnostics {
+			if d.Pos != first[i].Pos || d.Message != first[i].Message {
+				return nil, fmt.Errorf("shuffle %d: got diagnostic %q at %v, want %q at %v",
+					seed, d.Message, pass.Fset.Position(d.Pos), first[i].Message, pass.Fset.Position(first[i].Pos))
+			}
+		}
+	}
+
+	for _, d := range first {
+		pass.Report(d.Diagnostic)
+	}
+	return first, nil
+}
+
+func TestOrderIndependentInference(t *testing.T) {
+	t.Parallel()
+
+	// The test packages are shared with the top-level analyzer.
+	testdata, err := filepath.Abs(filepath.Join("..", "testdata"))
+	require.NoError(t, err)
+	for _, pkg := range []string{"go.uber.org/inference", "go.uber.org/receivers/inference", "go.uber.org/errorreturn/inference"} {
+		pkg := pkg
+		t.Run(pkg, func(t *testing.T) {
+			t.Parallel()
+
+			results := analysistest.Run(t, testdata, _shuffleAnalyzer, pkg)
+			require.Len(t, results, 1)
+			require.NoError(t, results[0].Err)
+			require.NotEmpty(t, results[0].Result)
+		})
+	}
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
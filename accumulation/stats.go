@@ -0,0 +1,133 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package accumulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion"
+	"go.uber.org/nilaway/assertion/affiliation"
+	"go.uber.org/nilaway/assertion/function"
+	"go.uber.org/nilaway/assertion/global"
+	"go.uber.org/nilaway/diagnostic"
+	"go.uber.org/nilaway/inference"
+	"golang.org/x/tools/go/analysis"
+)
+
+// packageStats are the performance statistics of analyzing a package, written as JSON in stats
+// mode (see config.StatsFlag). All durations are in milliseconds.
+type packageStats struct {
+	Package string `json:"package"`
+	// DurationsMs are the wall-clock time spent in the sub-analyzers, keyed by "annotation",
+	// "function", "affiliation", "global" and "accumulation" (which includes inference).
+	DurationsMs       map[string]float64 `json:"durationsMs"`
+	FullTriggers      int                `json:"fullTriggers"`
+	ImplicationEdges  int                `json:"implicationEdges"`
+	ExportedFactBytes int                `json:"exportedFactBytes"`
+	Funcs             []funcStats        `json:"funcs"`
+}
+
+// funcStats are the performance statistics of analyzing a function.
+type funcStats struct {
+	Name         string  `json:"name"`
+	Pos          string  `json:"pos"`
+	DurationMs   float64 `json:"durationMs"`
+	Rounds       int     `json:"rounds"`
+	MaxTreeSize  int     `json:"maxTreeSize"`
+	FullTriggers int     `json:"fullTriggers"`
+}
+
+// writeStats collects the performance statistics of the current package, where accumulation is
+// the time spent in the accumulation analyzer and exported is the exported fact (if any), and
+// writes them as JSON to "<dir>/<package path>.json" (or prints them to stderr if dir is "-").
+// A diagnostic is returned if it fails to do so.
+func writeStats(pass *analysis.Pass, dir string, accumulation time.Duration, inferredMap *inference.InferredMap,
+	exported *inference.InferredMap) []diagnostic.Diagnostic {
+	if len(pass.Files) == 0 {
+		return nil
+	}
+	report := func(err error) []diagnostic.Diagnostic {
+		return []diagnostic.Diagnostic{{Diagnostic: analysis.Diagnostic{
+			Pos:     pass.Files[0].Package,
+			Message: fmt.Sprintf("Cannot write performance statistics: %v", err),
+		}}}
+	}
+
+	functionResult := pass.ResultOf[function.Analyzer].(function.Result)
+	stats := packageStats{
+		Package: pass.Pkg.Path(),
+		DurationsMs: map[string]float64{
+			"annotation":   milliseconds(pass.ResultOf[annotation.Analyzer].(annotation.Result).Duration),
+			"function":     milliseconds(functionResult.Duration),
+			"affiliation":  milliseconds(pass.ResultOf[affiliation.Analyzer].(affiliation.Result).Duration),
+			"global":       milliseconds(pass.ResultOf[global.Analyzer].(global.Result).Duration),
+			"accumulation": milliseconds(accumulation),
+		},
+		FullTriggers:     len(pass.ResultOf[assertion.Analyzer].(assertion.Result).FullTriggers),
+		ImplicationEdges: inferredMap.NumImplications(),
+		Funcs:            make([]funcStats, 0, len(functionResult.FuncStats)),
+	}
+	if exported != nil {
+		// The facts are encoded by the driver, so here we encode the exported map again for its size.
+		content, err := exported.GobEncode()
+		if err != nil {
+			return report(err)
+		}
+		stats.ExportedFactBytes = len(content)
+	}
+	for _, f := range functionResult.FuncStats {
+		stats.Funcs = append(stats.Funcs, funcStats{
+			Name:         f.Name,
+			Pos:          pass.Fset.Position(f.Pos).String(),
+			DurationMs:   milliseconds(f.Duration),
+			Rounds:       f.Rounds,
+			MaxTreeSize:  f.MaxTreeSize,
+			FullTriggers: f.NumFullTriggers,
+		})
+	}
+
+	if dir == "-" {
+		content, err := json.Marshal(stats)
+		if err != nil {
+			return report(err)
+		}
+		if _, err := fmt.Fprintln(os.Stderr, string(content)); err != nil {
+			return report(err)
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return report(err)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(pass.Pkg.Path())+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return report(err)
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return report(err)
+	}
+	return nil
+}
+
+// milliseconds returns the duration in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
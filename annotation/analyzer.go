@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime/debug"
+	"time"
 
 	"go.uber.org/nilaway/config"
 	"golang.org/x/tools/go/analysis"
@@ -34,6 +35,8 @@ type Result struct {
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
 	// to do with them.
 	Errors []error
+	// Duration is the wall-clock time spent in the analyzer.
+	Duration time.Duration
 }
 
 // Analyzer here is the analyzer than reads annotations and passes them onto the accumulator to
@@ -62,11 +65,16 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		}
 	}()
 
+	start := time.Now()
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 
 	if !conf.IsPkgInScope(pass.Pkg) {
 		return Result{AnnotationMap: new(ObservedMap)}, nil
 	}
 
-	return Result{AnnotationMap: newObservedMap(pass, pass.Files)}, nil
+	annotationMap := newObservedMap(pass, pass.Files)
+	if err := annotationMap.readStubAnnotations(pass, conf); err != nil {
+		return Result{AnnotationMap: annotationMap, Errors: []error{err}}, nil
+	}
+	return Result{AnnotationMap: annotationMap, Duration: time.Since(start)}, nil
 }
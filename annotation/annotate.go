@@ -0,0 +1,307 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+)
+
+// _annotatableName matches the names that can be referred to in the annotations, e.g., names
+// with underscores cannot be parsed by nilabilityFromCommentGroup.
+var _annotatableName = regexp.MustCompile("^" + identRegexStr + "$")
+
+// annotatableSite is a site of a declaration that can be annotated in the doc comment of the
+// declaration, e.g., a param of a function or a field of a struct.
+type annotatableSite struct {
+	// name is the name of the site in the annotations, e.g., "x" or "result 0", or empty if the
+	// site cannot be referred to (e.g., a param named "_").
+	name string
+	// typ is the type of the site.
+	typ types.Type
+	// key is the key of the site for looking up the inferred annotations.
+	key Key
+	// handWritten is the annotation of the site read from the doc comment.
+	handWritten Val
+}
+
+// InferredAnnotations provides the inferred annotations of the sites.
+type InferredAnnotations interface {
+	// CheckInferred returns the inferred nilability of the shallow or deep site of the key, and
+	// false if the site is not determined.
+	CheckInferred(key Key, isDeep bool) (bool, bool)
+}
+
+// Annotate compares the inferred annotations of the params, results, fields and global variables
+// declared in the current package against the hand-written annotations, and returns the
+// diagnostics that
+//   - suggest fixes adding the inferred annotations to the doc comments of the declarations, for
+//     the sites that are not annotated yet;
+//   - report the hand-written annotations that disagree with the inferred ones.
+//
+// The hand-written annotations are always left intact. Note that the test variants of the
+// packages are skipped, since the source files shared with the non-test variants would otherwise
+// receive (possibly conflicting) edits twice.
+func Annotate(pass *analysis.Pass, handWritten *ObservedMap, inferred InferredAnnotations) []analysis.Diagnostic {
+	conf := pass.ResultOf[config.Analyzer].(*config.Config)
+	for _, file := range pass.Files {
+		if strings.HasSuffix(pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			return nil
+		}
+	}
+
+	var diagnostics []analysis.Diagnostic
+	for _, file := range pass.Files {
+		if !conf.IsFileInScope(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				funcObj, ok := pass.TypesInfo.ObjectOf(decl.Name).(*types.Func)
+				if !ok {
+					continue
+				}
+				sites := funcSites(funcObj, handWritten)
+				diagnostics = append(diagnostics, annotateDecl(pass, conf, inferred, decl.Name, decl.Pos(), sites)...)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					// Similar to newObservedMap, the annotations of a single-spec declaration are
+					// read from the doc comment of the declaration, otherwise from that of the spec.
+					pos := decl.Pos()
+					if len(decl.Specs) > 1 {
+						pos = spec.Pos()
+					}
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						if decl.Tok != token.VAR {
+							continue
+						}
+						var sites []annotatableSite
+						for _, name := range spec.Names {
+							varObj, ok := pass.TypesInfo.ObjectOf(name).(*types.Var)
+							if !ok {
+								continue
+							}
+							sites = append(sites, annotatableSite{
+								name:        siteName(varObj.Name(), ""),
+								typ:         varObj.Type(),
+								key:         &GlobalVarAnnotationKey{VarDecl: varObj},
+								handWritten: handWritten.globalVarsAnnMap[varObj],
+							})
+						}
+						diagnostics = append(diagnostics, annotateDecl(pass, conf, inferred, spec.Names[0], pos, sites)...)
+					case *ast.TypeSpec:
+						diagnostics = append(diagnostics, annotateTypeSpec(pass, conf, spec, pos, handWritten, inferred)...)
+					}
+				}
+			}
+		}
+	}
+	return diagnostics
+}
+
+// annotateTypeSpec returns the diagnostics for the fields of a struct type, or the methods of an
+// interface type.
+func annotateTypeSpec(pass *analysis.Pass, conf *config.Config, spec *ast.TypeSpec, pos token.Pos,
+	handWritten *ObservedMap, inferred InferredAnnotations) []analysis.Diagnostic {
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		var sites []annotatableSite
+		for _, field := range t.Fields.List {
+			for _, name := range field.Names {
+				fieldObj, ok := pass.TypesInfo.ObjectOf(name).(*types.Var)
+				if !ok {
+					continue
+				}
+				sites = append(sites, annotatableSite{
+					name:        siteName(fieldObj.Name(), ""),
+					typ:         fieldObj.Type(),
+					key:         &FieldAnnotationKey{FieldDecl: fieldObj},
+					handWritten: handWritten.fieldAnnMap[fieldObj],
+				})
+			}
+		}
+		return annotateDecl(pass, conf, inferred, spec.Name, pos, sites)
+	case *ast.InterfaceType:
+		var diagnostics []analysis.Diagnostic
+		for _, method := range t.Methods.List {
+			if len(method.Names) != 1 {
+				continue
+			}
+			funcObj, ok := pass.TypesInfo.ObjectOf(method.Names[0]).(*types.Func)
+			if !ok {
+				continue
+			}
+			sites := funcSites(funcObj, handWritten)
+			diagnostics = append(diagnostics, annotateDecl(pass, conf, inferred, method.Names[0], method.Pos(), sites)...)
+		}
+		return diagnostics
+	}
+	return nil
+}
+
+// funcSites returns the annotatable sites of the params and results of the function.
+func funcSites(funcObj *types.Func, handWritten *ObservedMap) []annotatableSite {
+	sig := funcObj.Type().(*types.Signature)
+	valAt := func(vals []Val, i int) Val {
+		if i < len(vals) {
+			return vals[i]
+		}
+		return EmptyVal
+	}
+
+	var sites []annotatableSite
+	for i := 0; i < sig.Params().Len(); i++ {
+		param := sig.Params().At(i)
+		t := param.Type()
+		// Variadic params are annotated as having type `T` instead of `[]T`.
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			if s, ok := t.(*types.Slice); ok {
+				t = s.Elem()
+			}
+		}
+		sites = append(sites, annotatableSite{
+			name:        siteName(param.Name(), paramStr(i)),
+			typ:         t,
+			key:         ParamKeyFromArgNum(funcObj, i),
+			handWritten: valAt(handWritten.funcParamAnnMap[funcObj], i),
+		})
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		result := sig.Results().At(i)
+		sites = append(sites, annotatableSite{
+			name:        siteName(result.Name(), resultStr(i)),
+			typ:         result.Type(),
+			key:         RetKeyFromRetNum(funcObj, i),
+			handWritten: valAt(handWritten.funcRetAnnMap[funcObj], i),
+		})
+	}
+	return sites
+}
+
+// siteName returns the name of the site in the annotations: named sites must be referred to by
+// their names (see newObservedMap), and unnamed ones by the index-based keys (e.g., "param 0").
+// An empty string is returned if the site cannot be referred to.
+func siteName(name string, indexKey string) string {
+	if name == "" {
+		return indexKey
+	}
+	if !_annotatableName.MatchString(name) {
+		return ""
+	}
+	return name
+}
+
+// deepSiteName returns the name of the deep nilability of the site in the annotations (e.g.,
+// "*x" for pointers, "x[]" for slices and maps, and "<-x" for channels), or an empty string if
+// the type of the site does not have deep nilability.
+func deepSiteName(name string, t types.Type) string {
+	switch t.Underlying().(type) {
+	case *types.Pointer:
+		return "*" + name
+	case *types.Slice, *types.Map:
+		return name + "[]"
+	case *types.Chan:
+		return "<-" + name
+	}
+	return ""
+}
+
+// annotateDecl returns the diagnostics for the sites of a declaration (see Annotate), where ident
+// is the identifier for reporting and pos is the position to insert the doc comment at.
+func annotateDecl(pass *analysis.Pass, conf *config.Config, inferred InferredAnnotations, ident *ast.Ident,
+	pos token.Pos, sites []annotatableSite) []analysis.Diagnostic {
+	var (
+		diagnostics     []analysis.Diagnostic
+		nilable, nonnil []string
+	)
+	// check compares the hand-written annotation (if set) with the inferred one (if determined),
+	// or records the inferred annotation to be added otherwise.
+	check := func(name string, handWrittenSet, handWrittenNilable, inferredNilable, determined bool) {
+		switch {
+		case !determined:
+		case handWrittenSet && handWrittenNilable != inferredNilable:
+			diagnostics = append(diagnostics, analysis.Diagnostic{
+				Pos: ident.Pos(),
+				Message: fmt.Sprintf("Hand-written annotation `%s(%s)` for `%s` disagrees with the inferred `%s(%s)`",
+					keyword(handWrittenNilable), name, ident.Name, keyword(inferredNilable), name),
+			})
+		case handWrittenSet:
+		case inferredNilable:
+			nilable = append(nilable, name)
+		default:
+			nonnil = append(nonnil, name)
+		}
+	}
+
+	for _, site := range sites {
+		if site.name == "" || util.TypeBarsNilness(site.typ) {
+			continue
+		}
+		nilable, determined := inferred.CheckInferred(site.key, false /* isDeep */)
+		check(site.name, site.handWritten.IsNilableSet, site.handWritten.IsNilable, nilable, determined)
+
+		// The deep nilability is only worth adding if it differs from the default one.
+		deepName := deepSiteName(site.name, site.typ)
+		if deepName == "" {
+			continue
+		}
+		deepNilable, deepDetermined := inferred.CheckInferred(site.key, true /* isDeep */)
+		if site.handWritten.IsDeepNilableSet || deepNilable != TypeIsDeepDefaultNilable(site.typ, conf) {
+			check(deepName, site.handWritten.IsDeepNilableSet, site.handWritten.IsDeepNilable, deepNilable, deepDetermined)
+		}
+	}
+
+	if len(nilable) == 0 && len(nonnil) == 0 {
+		return diagnostics
+	}
+	var seqs []string
+	if len(nilable) > 0 {
+		seqs = append(seqs, fmt.Sprintf("%s(%s)", nilableKeyword, strings.Join(nilable, sep+" ")))
+	}
+	if len(nonnil) > 0 {
+		seqs = append(seqs, fmt.Sprintf("%s(%s)", nonNilKeyword, strings.Join(nonnil, sep+" ")))
+	}
+	comment := "// " + strings.Join(seqs, " ")
+
+	// The doc comment is inserted right before the declaration (i.e., after the existing doc
+	// comment if any) with the same indentation, assuming the code is gofmt-ed.
+	indent := strings.Repeat("\t", pass.Fset.Position(pos).Column-1)
+	return append(diagnostics, analysis.Diagnostic{
+		Pos:     ident.Pos(),
+		Message: fmt.Sprintf("Inferred annotation for `%s`: %s", ident.Name, comment),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Add the inferred annotation to the doc comment",
+			TextEdits: []analysis.TextEdit{{Pos: pos, End: pos, NewText: []byte(comment + "\n" + indent)}},
+		}},
+	})
+}
+
+// keyword returns the annotation keyword for the nilability.
+func keyword(nilable bool) string {
+	if nilable {
+		return nilableKeyword
+	}
+	return nonNilKeyword
+}
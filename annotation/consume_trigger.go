@@ -518,6 +518,125 @@ func (s SliceAccessPrestring) String() string {
 	return sb.String()
 }
 
+// TypeAssert is when an interface value flows to a point where it is type-asserted in the
+// single-value form (e.g., `x.(*T)`), which panics if the value is nil, and thus must be non-nil
+type TypeAssert struct {
+	*ConsumeTriggerTautology
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (t *TypeAssert) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*TypeAssert); ok {
+		return t.ConsumeTriggerTautology.equals(other.ConsumeTriggerTautology)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (t *TypeAssert) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *t
+	copyConsumer.ConsumeTriggerTautology = t.ConsumeTriggerTautology.Copy().(*ConsumeTriggerTautology)
+	return &copyConsumer
+}
+
+// Prestring returns this TypeAssert as a Prestring
+func (t *TypeAssert) Prestring() Prestring {
+	return TypeAssertPrestring{
+		AssignmentStr: t.assignmentFlow.String(),
+	}
+}
+
+// TypeAssertPrestring is a Prestring storing the needed information to compactly encode a TypeAssert
+type TypeAssertPrestring struct {
+	AssignmentStr string
+}
+
+func (t TypeAssertPrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString("type-asserted without `ok` check")
+	sb.WriteString(t.AssignmentStr)
+	return sb.String()
+}
+
+// ChanSentTo is when a channel value flows to a point where a value is sent to it outside of a
+// `select` statement, which blocks forever if the channel is nil, and thus must be non-nil
+type ChanSentTo struct {
+	*ConsumeTriggerTautology
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (c *ChanSentTo) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*ChanSentTo); ok {
+		return c.ConsumeTriggerTautology.equals(other.ConsumeTriggerTautology)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (c *ChanSentTo) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *c
+	copyConsumer.ConsumeTriggerTautology = c.ConsumeTriggerTautology.Copy().(*ConsumeTriggerTautology)
+	return &copyConsumer
+}
+
+// Prestring returns this ChanSentTo as a Prestring
+func (c *ChanSentTo) Prestring() Prestring {
+	return ChanSentToPrestring{
+		AssignmentStr: c.assignmentFlow.String(),
+	}
+}
+
+// ChanSentToPrestring is a Prestring storing the needed information to compactly encode a ChanSentTo
+type ChanSentToPrestring struct {
+	AssignmentStr string
+}
+
+func (c ChanSentToPrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString("sent to")
+	sb.WriteString(c.AssignmentStr)
+	return sb.String()
+}
+
+// ChanClose is when a channel value flows to a point where it is closed, which panics if the channel is nil, and thus must be non-nil
+type ChanClose struct {
+	*ConsumeTriggerTautology
+}
+
+// equals returns true if the passed ConsumingAnnotationTrigger is equal to this one
+func (c *ChanClose) equals(other ConsumingAnnotationTrigger) bool {
+	if other, ok := other.(*ChanClose); ok {
+		return c.ConsumeTriggerTautology.equals(other.ConsumeTriggerTautology)
+	}
+	return false
+}
+
+// Copy returns a deep copy of this ConsumingAnnotationTrigger
+func (c *ChanClose) Copy() ConsumingAnnotationTrigger {
+	copyConsumer := *c
+	copyConsumer.ConsumeTriggerTautology = c.ConsumeTriggerTautology.Copy().(*ConsumeTriggerTautology)
+	return &copyConsumer
+}
+
+// Prestring returns this ChanClose as a Prestring
+func (c *ChanClose) Prestring() Prestring {
+	return ChanClosePrestring{
+		AssignmentStr: c.assignmentFlow.String(),
+	}
+}
+
+// ChanClosePrestring is a Prestring storing the needed information to compactly encode a ChanClose
+type ChanClosePrestring struct {
+	AssignmentStr string
+}
+
+func (c ChanClosePrestring) String() string {
+	var sb strings.Builder
+	sb.WriteString("closed")
+	sb.WriteString(c.AssignmentStr)
+	return sb.String()
+}
+
 // FldAccess is when a value flows to a point where a field of it is accessed, and so it must be non-nil
 type FldAccess struct {
 	*ConsumeTriggerTautology
@@ -31,6 +31,9 @@ var initStructsConsumingAnnotationTrigger = []any{
 	&MapAccess{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&MapWrittenTo{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&SliceAccess{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
+	&TypeAssert{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
+	&ChanSentTo{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
+	&ChanClose{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&FldAccess{ConsumeTriggerTautology: &ConsumeTriggerTautology{}},
 	&UseAsErrorResult{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
 	&FldAssign{TriggerIfNonNil: &TriggerIfNonNil{Ann: newMockKey()}},
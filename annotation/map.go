@@ -276,6 +276,12 @@ var seqRegex = regexp.MustCompile(seqRegexStr)
 
 type nilabilitySet map[string]Val
 
+// HasNilabilityAnnotations returns true if the comment group contains any nilability annotation
+// (e.g., `// nilable(x)`).
+func HasNilabilityAnnotations(group *ast.CommentGroup) bool {
+	return len(nilabilityFromCommentGroup(group)) > 0
+}
+
 // from a CommentGroup return a nilabilitySet of which identifiers are known annotated nilable
 func nilabilityFromCommentGroup(group *ast.CommentGroup) nilabilitySet {
 	set := make(nilabilitySet)
@@ -348,12 +354,18 @@ func nilabilityFromCommentGroup(group *ast.CommentGroup) nilabilitySet {
 }
 
 // TypeIsDefaultNilable takes a type and returns true iff we assume default nilability for that
-// type - in contrast to the remaining cases, in which we assume default non-nil.
-func TypeIsDefaultNilable(t types.Type) bool {
+// type - in contrast to the remaining cases, in which we assume default non-nil. The config
+// provides the user-declared default nilable and default nonnil types.
+func TypeIsDefaultNilable(t types.Type, conf *config.Config) bool {
 	if t == nil {
 		return false
 	}
 
+	// Types declared as default nonnil by the users take precedence over all other rules.
+	if conf.IsDefaultNonnilType(t) {
+		return false
+	}
+
 	// Builtin error type should be nilable by default.
 	if types.Identical(t, util.ErrorType) {
 		return true
@@ -366,20 +378,13 @@ func TypeIsDefaultNilable(t types.Type) bool {
 	}
 
 	// Additionally, we allow custom default nilable types provided by the users.
-	if t, ok := t.(*types.Named); ok {
-		for _, defaultNilableNamedType := range config.DefaultNilableNamedTypes {
-			if t.String() == defaultNilableNamedType {
-				return true
-			}
-		}
-	}
-	return false
+	return conf.IsDefaultNilableType(t)
 }
 
 // TypeIsDeepDefaultNilable takes an `ast.Expr` that evaluates to a type, and returns true iff
 // we assume default deep nilability for that type - in contrast to the remaining cases, in which
 // we assume default deep non-nil.
-func TypeIsDeepDefaultNilable(t types.Type) bool {
+func TypeIsDeepDefaultNilable(t types.Type, conf *config.Config) bool {
 	switch t := t.(type) {
 	case *types.Array:
 		// the array case is handled different from others, since an array is not default nilable,
@@ -387,20 +392,20 @@ func TypeIsDeepDefaultNilable(t types.Type) bool {
 
 		// recurse if multi-dimensional array until containing type is reached
 		if e, ok := t.Elem().(*types.Array); ok {
-			return TypeIsDeepDefaultNilable(e)
+			return TypeIsDeepDefaultNilable(e, conf)
 		}
 		// assign deep nilability based on the element type
 		return !util.TypeBarsNilness(t.Elem())
 	case *types.Slice:
-		return TypeIsDefaultNilable(t.Elem())
+		return TypeIsDefaultNilable(t.Elem(), conf)
 	case *types.Map:
-		return TypeIsDefaultNilable(t.Elem())
+		return TypeIsDefaultNilable(t.Elem(), conf)
 	case *types.Pointer:
-		return TypeIsDefaultNilable(t.Elem())
+		return TypeIsDefaultNilable(t.Elem(), conf)
 	case *types.Chan:
-		return TypeIsDefaultNilable(t.Elem())
+		return TypeIsDefaultNilable(t.Elem(), conf)
 	case *types.Named:
-		return TypeIsDeepDefaultNilable(t.Underlying())
+		return TypeIsDeepDefaultNilable(t.Underlying(), conf)
 	}
 	return false
 }
@@ -409,16 +414,16 @@ func TypeIsDeepDefaultNilable(t types.Type) bool {
 // set. If it is, then that Annotation is returned. If not, then `nonNil` is returned.
 // the type of the Annotation site is also passed, and it can possibly serve to mark a site
 // as `nilable` when its Annotation doesn't indicate so.
-func (set nilabilitySet) checkNilability(name string, t types.Type) Val {
+func (set nilabilitySet) checkNilability(name string, t types.Type, conf *config.Config) Val {
 	val := EmptyVal
 	if v, ok := set[name]; ok {
 		val = v
 	}
 	// in each of the following cases, isFinalVal=false because defaults are not considered final
-	if TypeIsDefaultNilable(t) {
+	if TypeIsDefaultNilable(t, conf) {
 		val = val.makeNilable(false)
 	}
-	if TypeIsDeepDefaultNilable(t) {
+	if TypeIsDeepDefaultNilable(t, conf) {
 		val = val.makeDeepNilable(false)
 	}
 	return val
@@ -467,7 +472,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 					lookupKey = resultStr(len(annVals))
 				}
 
-				annVals = append(annVals, set.checkNilability(lookupKey, typeOf(field.Type)))
+				annVals = append(annVals, set.checkNilability(lookupKey, typeOf(field.Type), conf))
 			} else {
 				for _, name := range field.Names {
 					declFld := pass.TypesInfo.ObjectOf(name).(*types.Var)
@@ -494,7 +499,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 					} else {
 						lookupKey = name.Name
 					}
-					annVals = append(annVals, set.checkNilability(lookupKey, fieldType))
+					annVals = append(annVals, set.checkNilability(lookupKey, fieldType, conf))
 				}
 			}
 		}
@@ -550,7 +555,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 								for _, name := range spec.Names {
 									varObj := pass.TypesInfo.ObjectOf(name).(*types.Var)
 									globalVarsAnnMap[varObj] =
-										docNilabilitySet.checkNilability(name.Name, typeOf(spec.Type))
+										docNilabilitySet.checkNilability(name.Name, typeOf(spec.Type), conf)
 								}
 							}
 						case *ast.TypeSpec:
@@ -563,7 +568,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 							readDeepNilability := func() {
 								typeName := pass.TypesInfo.ObjectOf(spec.Name).(*types.TypeName)
 								deepTypeAnnMap[typeName] =
-									docNilabilitySet.checkNilability(spec.Name.Name, typeOf(spec.Type))
+									docNilabilitySet.checkNilability(spec.Name.Name, typeOf(spec.Type), conf)
 							}
 							var handleTypeVal func(expr ast.Expr)
 							handleTypeVal = func(expr ast.Expr) {
@@ -572,7 +577,7 @@ func newObservedMap(pass *analysis.Pass, files []*ast.File) *ObservedMap {
 									for _, field := range typeVal.Fields.List {
 										for _, name := range field.Names {
 											fieldAnnMap[pass.TypesInfo.ObjectOf(name).(*types.Var)] =
-												docNilabilitySet.checkNilability(name.Name, typeOf(field.Type))
+												docNilabilitySet.checkNilability(name.Name, typeOf(field.Type), conf)
 										}
 									}
 								case *ast.InterfaceType:
@@ -457,6 +457,33 @@ func (BlankVarReturnPrestring) String() string {
 	return "return via a blank variable `_`"
 }
 
+// FailedTypeAssert is when a value is determined to flow from the value of a comma-ok type
+// assertion (e.g., `v, ok := x.(*T)`) that is not guarded by a check of `ok`, i.e., the zero
+// value of the asserted type if the assertion fails
+type FailedTypeAssert struct {
+	*ProduceTriggerTautology
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (f *FailedTypeAssert) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*FailedTypeAssert); ok {
+		return f.ProduceTriggerTautology.equals(other.ProduceTriggerTautology)
+	}
+	return false
+}
+
+// Prestring returns this Prestring as a Prestring
+func (*FailedTypeAssert) Prestring() Prestring {
+	return FailedTypeAssertPrestring{}
+}
+
+// FailedTypeAssertPrestring is a Prestring storing the needed information to compactly encode a FailedTypeAssert
+type FailedTypeAssertPrestring struct{}
+
+func (FailedTypeAssertPrestring) String() string {
+	return "value of a possibly failed type assertion"
+}
+
 // DuplicateParamProducer duplicates a given produce trigger, assuming the given produce trigger
 // is of FuncParam.
 func DuplicateParamProducer(t *ProduceTrigger, location token.Position) *ProduceTrigger {
@@ -652,6 +679,74 @@ func (TrustedFuncNonnilPrestring) String() string {
 	return "determined to be nonnil by a trusted function"
 }
 
+// FuncReturnNonnilByContract is used when a result of a function call is determined to be nonnil
+// by a contract of the function (e.g., `contract(true -> nonnil)`) matching the call
+type FuncReturnNonnilByContract struct {
+	*ProduceTriggerNever
+	FuncName string
+	RetNum   int
+	Contract string
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (f *FuncReturnNonnilByContract) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*FuncReturnNonnilByContract); ok {
+		return f.ProduceTriggerNever.equals(other.ProduceTriggerNever) && f.FuncName == other.FuncName &&
+			f.RetNum == other.RetNum && f.Contract == other.Contract
+	}
+	return false
+}
+
+// Prestring returns this FuncReturnNonnilByContract as a Prestring
+func (f *FuncReturnNonnilByContract) Prestring() Prestring {
+	return FuncReturnNonnilByContractPrestring{f.FuncName, f.RetNum, f.Contract}
+}
+
+// FuncReturnNonnilByContractPrestring is a Prestring storing the needed information to compactly encode a FuncReturnNonnilByContract
+type FuncReturnNonnilByContractPrestring struct {
+	FuncName string
+	RetNum   int
+	Contract string
+}
+
+func (f FuncReturnNonnilByContractPrestring) String() string {
+	return fmt.Sprintf("result %d of `%s()` determined to be nonnil by `%s`", f.RetNum, f.FuncName, f.Contract)
+}
+
+// FuncReturnNilByContract is used when a result of a function call is determined to be nil by a
+// contract of the function (e.g., `contract(nil -> nil)`) matching the call
+type FuncReturnNilByContract struct {
+	*ProduceTriggerTautology
+	FuncName string
+	RetNum   int
+	Contract string
+}
+
+// equals returns true if the passed ProducingAnnotationTrigger is equal to this one
+func (f *FuncReturnNilByContract) equals(other ProducingAnnotationTrigger) bool {
+	if other, ok := other.(*FuncReturnNilByContract); ok {
+		return f.ProduceTriggerTautology.equals(other.ProduceTriggerTautology) && f.FuncName == other.FuncName &&
+			f.RetNum == other.RetNum && f.Contract == other.Contract
+	}
+	return false
+}
+
+// Prestring returns this FuncReturnNilByContract as a Prestring
+func (f *FuncReturnNilByContract) Prestring() Prestring {
+	return FuncReturnNilByContractPrestring{f.FuncName, f.RetNum, f.Contract}
+}
+
+// FuncReturnNilByContractPrestring is a Prestring storing the needed information to compactly encode a FuncReturnNilByContract
+type FuncReturnNilByContractPrestring struct {
+	FuncName string
+	RetNum   int
+	Contract string
+}
+
+func (f FuncReturnNilByContractPrestring) String() string {
+	return fmt.Sprintf("result %d of `%s()` determined to be nil by `%s`", f.RetNum, f.FuncName, f.Contract)
+}
+
 // FldRead is used when a value is determined to flow from a read to a field
 type FldRead struct {
 	*TriggerIfNilable
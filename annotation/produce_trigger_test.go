@@ -50,12 +50,15 @@ func (s *ProducingAnnotationTriggerTestSuite) SetupTest() {
 		&UnassignedFld{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&NoVarAssign{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&BlankVarReturn{ProduceTriggerTautology: &ProduceTriggerTautology{}},
+		&FailedTypeAssert{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&FuncParam{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&MethodRecv{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&MethodRecvDeep{TriggerIfDeepNilable: &TriggerIfDeepNilable{Ann: mockedKey}},
 		&VariadicFuncParam{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&TrustedFuncNilable{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&TrustedFuncNonnil{ProduceTriggerNever: &ProduceTriggerNever{}},
+		&FuncReturnNonnilByContract{ProduceTriggerNever: &ProduceTriggerNever{}},
+		&FuncReturnNilByContract{ProduceTriggerTautology: &ProduceTriggerTautology{}},
 		&FldRead{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&ParamFldRead{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
 		&FldReturn{TriggerIfNilable: &TriggerIfNilable{Ann: mockedKey}},
@@ -0,0 +1,112 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"fmt"
+	"go/types"
+	"strconv"
+	"strings"
+)
+
+// SplitSiteQuery splits a site query (see LookupSite) into the package path and the rest of the
+// query. Note that package paths may contain dots (e.g., "go.uber.org/foo"), so the package path
+// ends at the first dot after the last slash.
+func SplitSiteQuery(query string) (pkgPath string, rest string) {
+	slash := strings.LastIndex(query, "/")
+	dot := strings.Index(query[slash+1:], ".")
+	if dot < 0 {
+		return query, ""
+	}
+	return query[:slash+1+dot], query[slash+1+dot+1:]
+}
+
+// LookupSite resolves the annotation site named by the query in the package, and returns the key
+// of the site along with the object declaring it (for reporting purposes). The query is in one of
+// the following forms:
+//   - "<pkg path>.<func>:<site>" or "<pkg path>.<type>.<method>:<site>" for a param, result or
+//     receiver of a function, where site is the name of the param, result or receiver, or an
+//     index-based key in the same syntax as the annotations (e.g., "param 0" or "result 0");
+//   - "<pkg path>.<type>.<field>" for a field of a struct;
+//   - "<pkg path>.<var>" for a global variable.
+//
+// An error is returned if the query does not name a site in the package.
+func LookupSite(pkg *types.Package, query string) (Key, types.Object, error) {
+	name, site, _ := strings.Cut(query, ":")
+	pkgPath, rest := SplitSiteQuery(name)
+	if pkgPath != pkg.Path() || rest == "" {
+		return nil, nil, fmt.Errorf("%q does not name a declaration in package %q", name, pkg.Path())
+	}
+
+	var obj types.Object
+	if typeName, member, ok := strings.Cut(rest, "."); ok {
+		t, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok {
+			return nil, nil, fmt.Errorf("no type named %q in package %q", typeName, pkgPath)
+		}
+		obj, _, _ = types.LookupFieldOrMethod(t.Type(), true /* addressable */, pkg, member)
+	} else {
+		obj = pkg.Scope().Lookup(rest)
+	}
+	if obj == nil {
+		return nil, nil, fmt.Errorf("no function, field or global variable named %q", name)
+	}
+
+	switch obj := obj.(type) {
+	case *types.Func:
+		if site == "" {
+			return nil, nil, fmt.Errorf("function %q requires a site (e.g., %q or %q)", name, paramStr(0), resultStr(0))
+		}
+		return lookupFuncSite(obj, site)
+	case *types.Var:
+		if site != "" {
+			return nil, nil, fmt.Errorf("%q is not a function and cannot have site %q", name, site)
+		}
+		if obj.IsField() {
+			return &FieldAnnotationKey{FieldDecl: obj}, obj, nil
+		}
+		return &GlobalVarAnnotationKey{VarDecl: obj}, obj, nil
+	}
+	return nil, nil, fmt.Errorf("no function, field or global variable named %q", name)
+}
+
+// lookupFuncSite resolves the param, result or receiver of the function named by the site.
+func lookupFuncSite(funcObj *types.Func, site string) (Key, types.Object, error) {
+	sig := funcObj.Type().(*types.Signature)
+	if s, ok := strings.CutPrefix(site, "param "); ok {
+		if i, err := strconv.Atoi(s); err == nil && i >= 0 && i < sig.Params().Len() {
+			return ParamKeyFromArgNum(funcObj, i), sig.Params().At(i), nil
+		}
+	}
+	if s, ok := strings.CutPrefix(site, "result "); ok {
+		if i, err := strconv.Atoi(s); err == nil && i >= 0 && i < sig.Results().Len() {
+			return RetKeyFromRetNum(funcObj, i), sig.Results().At(i), nil
+		}
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		if sig.Params().At(i).Name() == site {
+			return ParamKeyFromArgNum(funcObj, i), sig.Params().At(i), nil
+		}
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		if sig.Results().At(i).Name() == site {
+			return RetKeyFromRetNum(funcObj, i), sig.Results().At(i), nil
+		}
+	}
+	if recv := sig.Recv(); recv != nil && recv.Name() == site {
+		return &RecvAnnotationKey{FuncDecl: funcObj}, recv, nil
+	}
+	return nil, nil, fmt.Errorf("function %q has no param, result or receiver %q", funcObj.FullName(), site)
+}
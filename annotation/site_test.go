@@ -0,0 +1,73 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupSite(t *testing.T) {
+	t.Parallel()
+
+	const src = `package foo
+
+type T struct{ f *int }
+
+func (t *T) M(p *int) (res *int) { return p }
+
+func F(*int, *int) *int { return nil }
+
+var V *T
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "foo.go", src, 0)
+	require.NoError(t, err)
+	pkg, err := new(types.Config).Check("go.uber.org/foo", fset, []*ast.File{file}, nil)
+	require.NoError(t, err)
+
+	for query, want := range map[string]string{
+		"go.uber.org/foo.F:param 1":  "Param 1: '' of Function F",
+		"go.uber.org/foo.F:result 0": "Result 0 of Function F",
+		"go.uber.org/foo.T.M:p":      "Param 0: 'p' of Function M",
+		"go.uber.org/foo.T.M:res":    "Result 0 of Function M",
+		"go.uber.org/foo.T.M:t":      "Receiver of Method M",
+		"go.uber.org/foo.T.f":        "Field f",
+		"go.uber.org/foo.V":          "Global Variable V",
+	} {
+		key, obj, err := LookupSite(pkg, query)
+		require.NoError(t, err, query)
+		require.NotNil(t, obj, query)
+		require.Equal(t, want, key.String(), query)
+	}
+
+	for _, query := range []string{
+		"go.uber.org/bar.F:param 0",
+		"go.uber.org/foo.F",
+		"go.uber.org/foo.F:param 2",
+		"go.uber.org/foo.F:x",
+		"go.uber.org/foo.V:x",
+		"go.uber.org/foo.G:param 0",
+		"go.uber.org/foo.U.f",
+	} {
+		_, _, err := LookupSite(pkg, query)
+		require.Error(t, err, query)
+	}
+}
@@ -0,0 +1,227 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/nilaway/config"
+	"golang.org/x/tools/go/analysis"
+	"gopkg.in/yaml.v3"
+)
+
+// stubFile is the content of an annotation stub file, which provides the annotations for the
+// declarations of a package that cannot be annotated in source (e.g., stdlib or third-party
+// packages). The stub file for a package is located at "<stub dir>/<package path>.yaml", and it
+// maps the names of the declarations to their annotations, written in the same syntax as the doc
+// comments:
+//
+//	# <stub dir>/net/http.yaml
+//	Request: nonnil(URL, Header)           # fields of a struct type
+//	Client.Do: nonnil(req, result 0)       # methods, "<type name>.<method name>"
+//	NewRequest: nonnil(param 0, result 0)  # functions
+//	DefaultClient: nonnil(DefaultClient)   # global variables
+//
+// The params and results of functions can be referred to either by their names or by their
+// indices (e.g., "param 0" and "result 0").
+type stubFile map[string]string
+
+// loadedStubFile is the result of loading a stub file.
+type loadedStubFile struct {
+	stub stubFile
+	err  error
+}
+
+// _stubFiles caches the loaded stub files (keyed by the file path), since the stubs for the same
+// packages are loaded for every package importing them.
+var _stubFiles sync.Map
+
+// loadStubFile loads the stub file for the package from the first stub directory containing it,
+// or returns nil if there is none.
+func loadStubFile(dirs []string, pkgPath string) (stubFile, error) {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, filepath.FromSlash(pkgPath)+".yaml")
+		if cached, ok := _stubFiles.Load(path); ok {
+			loaded := cached.(loadedStubFile)
+			if loaded.stub == nil && loaded.err == nil {
+				continue
+			}
+			return loaded.stub, loaded.err
+		}
+
+		var loaded loadedStubFile
+		content, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			loaded.stub = make(stubFile)
+			decoder := yaml.NewDecoder(bytes.NewReader(content))
+			if err := decoder.Decode(&loaded.stub); err != nil && !errors.Is(err, io.EOF) {
+				loaded.stub, loaded.err = nil, fmt.Errorf("parse annotation stub file %q: %w", path, err)
+			}
+		case !errors.Is(err, os.ErrNotExist):
+			loaded.err = fmt.Errorf("read annotation stub file: %w", err)
+		}
+		_stubFiles.Store(path, loaded)
+		if loaded.stub != nil || loaded.err != nil {
+			return loaded.stub, loaded.err
+		}
+	}
+	return nil, nil
+}
+
+// readStubAnnotations reads the annotations from the stub files of the current package and the
+// packages whose declarations are referenced in it, and merges them into the map as if they were
+// syntactic annotations.
+func (m *ObservedMap) readStubAnnotations(pass *analysis.Pass, conf *config.Config) error {
+	dirs := conf.AnnotationStubDirs()
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	pkgs := map[*types.Package]bool{pass.Pkg: true}
+	for _, imported := range pass.Pkg.Imports() {
+		pkgs[imported] = true
+	}
+	// Declarations of indirectly imported packages can also be referenced, e.g., the field `Path`
+	// in `req.URL.Path`, where `req` is of type `*http.Request`.
+	for _, obj := range pass.TypesInfo.Uses {
+		if obj.Pkg() != nil {
+			pkgs[obj.Pkg()] = true
+		}
+	}
+	sorted := make([]*types.Package, 0, len(pkgs))
+	for pkg := range pkgs {
+		sorted = append(sorted, pkg)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path() < sorted[j].Path() })
+
+	var errs []error
+	for _, pkg := range sorted {
+		stub, err := loadStubFile(dirs, pkg.Path())
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if stub == nil {
+			continue
+		}
+		if err := m.applyStub(pkg, stub, conf); err != nil {
+			errs = append(errs, fmt.Errorf("apply annotation stub for package %q: %w", pkg.Path(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyStub stores the annotations in the stub file for the declarations of the package.
+func (m *ObservedMap) applyStub(pkg *types.Package, stub stubFile, conf *config.Config) error {
+	// Sort the names for deterministic iteration order.
+	names := make([]string, 0, len(stub))
+	for name := range stub {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		set := nilabilityFromCommentGroup(&ast.CommentGroup{List: []*ast.Comment{{Text: stub[name]}}})
+
+		// Methods are specified as "<type name>.<method name>".
+		if typeName, methodName, ok := strings.Cut(name, "."); ok {
+			obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+			if !ok {
+				return fmt.Errorf("no type named %q", typeName)
+			}
+			method, _, _ := types.LookupFieldOrMethod(obj.Type(), true /* addressable */, pkg, methodName)
+			funcObj, ok := method.(*types.Func)
+			if !ok {
+				return fmt.Errorf("no method named %q", name)
+			}
+			m.applyStubToFunc(funcObj, set, conf)
+			continue
+		}
+
+		switch obj := pkg.Scope().Lookup(name).(type) {
+		case *types.Func:
+			m.applyStubToFunc(obj, set, conf)
+		case *types.Var:
+			m.globalVarsAnnMap[obj] = set.checkNilability(name, obj.Type(), conf)
+		case *types.TypeName:
+			switch t := obj.Type().Underlying().(type) {
+			case *types.Struct:
+				for i := 0; i < t.NumFields(); i++ {
+					field := t.Field(i)
+					m.fieldAnnMap[field] = set.checkNilability(field.Name(), field.Type(), conf)
+				}
+			case *types.Pointer, *types.Map, *types.Slice, *types.Array:
+				m.deepTypeAnnMap[obj] = set.checkNilability(name, t, conf)
+			default:
+				return fmt.Errorf("type %q cannot be annotated, annotate its methods instead", name)
+			}
+		default:
+			return fmt.Errorf("no function, variable or type named %q", name)
+		}
+	}
+	return nil
+}
+
+// applyStubToFunc stores the annotations for the params, results and receiver of the function.
+func (m *ObservedMap) applyStubToFunc(funcObj *types.Func, set nilabilitySet, conf *config.Config) {
+	sig := funcObj.Type().(*types.Signature)
+
+	// lookupKey returns the name of the param or result if it is annotated by name, otherwise the
+	// index-based key (e.g., "param 0").
+	lookupKey := func(v *types.Var, indexKey string) string {
+		if _, ok := set[v.Name()]; ok && v.Name() != "" {
+			return v.Name()
+		}
+		return indexKey
+	}
+
+	params := make([]Val, sig.Params().Len())
+	for i := range params {
+		param := sig.Params().At(i)
+		t := param.Type()
+		// Similar to the syntactic annotations, variadic params are treated as having type `T`
+		// instead of `[]T`.
+		if sig.Variadic() && i == len(params)-1 {
+			if s, ok := t.(*types.Slice); ok {
+				t = s.Elem()
+			}
+		}
+		params[i] = set.checkNilability(lookupKey(param, paramStr(i)), t, conf)
+	}
+	m.funcParamAnnMap[funcObj] = params
+
+	results := make([]Val, sig.Results().Len())
+	for i := range results {
+		result := sig.Results().At(i)
+		results[i] = set.checkNilability(lookupKey(result, resultStr(i)), result.Type(), conf)
+	}
+	m.funcRetAnnMap[funcObj] = results
+
+	// Interface methods do not have receiver annotations.
+	if recv := sig.Recv(); recv != nil && !types.IsInterface(recv.Type()) {
+		m.funcRecvAnnMap[funcObj] = set.checkNilability(recv.Name(), recv.Type(), conf)
+	}
+}
@@ -0,0 +1,56 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadStubFile(t *testing.T) {
+	t.Parallel()
+
+	first, second := t.TempDir(), t.TempDir()
+	for path, content := range map[string]string{
+		filepath.Join(first, "net", "http.yaml"):    "Request: nonnil(URL)\n",
+		filepath.Join(second, "net", "http.yaml"):   "Request: nilable(URL)\n",
+		filepath.Join(second, "os.yaml"):            "Getenv: nonnil(result 0)\n",
+		filepath.Join(second, "example.com/x.yaml"): "[invalid",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	dirs := []string{first, second}
+
+	// The stub file in the first directory containing it is used.
+	stub, err := loadStubFile(dirs, "net/http")
+	require.NoError(t, err)
+	require.Equal(t, stubFile{"Request": "nonnil(URL)"}, stub)
+
+	stub, err = loadStubFile(dirs, "os")
+	require.NoError(t, err)
+	require.Equal(t, stubFile{"Getenv": "nonnil(result 0)"}, stub)
+
+	// Missing stub files are not errors.
+	stub, err = loadStubFile(dirs, "fmt")
+	require.NoError(t, err)
+	require.Nil(t, stub)
+
+	_, err = loadStubFile(dirs, "example.com/x")
+	require.ErrorContains(t, err, "parse annotation stub file")
+}
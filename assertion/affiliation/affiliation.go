@@ -17,11 +17,14 @@
 package affiliation
 
 import (
+	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"strings"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function/functioncontracts"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
@@ -33,6 +36,11 @@ import (
 type Affiliation struct {
 	conf     *config.Config
 	triggers []annotation.FullTrigger
+	// contracts stores the function contracts of the current and upstream packages, which are
+	// checked for the implementations of the interface methods with contracts.
+	contracts functioncontracts.Map
+	// diagnostics stores the implementations not redeclaring the contracts of the interface methods.
+	diagnostics []analysis.Diagnostic
 }
 
 // Pair is a struct to store struct-interface affiliation pairs
@@ -87,8 +95,8 @@ func (a *Affiliation) extractAffiliations(pass *analysis.Pass) {
 // computeTriggersForCastingSites analyzes all explicit and implicit sites of casts in the AST. For example, explicit casts,
 // variable assignments, variable declaration and initialization, method returns, and method parameters.
 func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstreamCache ImplementedDeclaredTypesCache, currentCache ImplementedDeclaredTypesCache) {
-	appendTypeToTypeTriggers := func(lhsType, rhsType types.Type) {
-		a.triggers = append(a.triggers, a.computeTriggersForTypes(lhsType, rhsType, upstreamCache, currentCache)...)
+	appendTypeToTypeTriggers := func(pos token.Pos, lhsType, rhsType types.Type) {
+		a.triggers = append(a.triggers, a.computeTriggersForTypes(pass, pos, lhsType, rhsType, upstreamCache, currentCache)...)
 	}
 
 	for _, file := range pass.Files {
@@ -114,7 +122,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 							for i := range node.Lhs {
 								lhsType := util.TypeOf(pass, node.Lhs[i])
 								rhsType := rhsSig.At(i).Type()
-								appendTypeToTypeTriggers(lhsType, rhsType)
+								appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 							}
 							return true
 						}
@@ -123,14 +131,14 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 					for i := 0; i < len(node.Lhs) && i < len(node.Rhs); i++ {
 						lhsType := util.TypeOf(pass, node.Lhs[i])
 						rhsType := util.TypeOf(pass, node.Rhs[i])
-						appendTypeToTypeTriggers(lhsType, rhsType)
+						appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 					}
 				case *ast.ValueSpec:
 					// e.g., var i I = &S{}
 					for i := 0; i < len(node.Values); i++ {
 						lhsType := util.TypeOf(pass, node.Type)
 						rhsType := util.TypeOf(pass, node.Values[i])
-						appendTypeToTypeTriggers(lhsType, rhsType)
+						appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 					}
 				case *ast.CallExpr:
 					// e.g., func foo(i I), foo(&S{})
@@ -141,7 +149,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 								for i := 0; i < fsig.Params().Len() && i < len(node.Args); i++ {
 									lhsType := fsig.Params().At(i).Type()      // receiver param of method declaration
 									rhsType := util.TypeOf(pass, node.Args[i]) // caller param
-									appendTypeToTypeTriggers(lhsType, rhsType)
+									appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 								}
 							}
 						}
@@ -152,7 +160,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 						for i := 1; i < len(node.Args); i++ {
 							lhsType := sliceType.Elem()
 							rhsType := util.TypeOf(pass, node.Args[i])
-							appendTypeToTypeTriggers(lhsType, rhsType)
+							appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 						}
 					}
 
@@ -160,7 +168,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 					// e.g., v, ok := i.(*S)
 					lhsType := util.TypeOf(pass, node.X)
 					rhsType := util.TypeOf(pass, node.Type)
-					appendTypeToTypeTriggers(lhsType, rhsType)
+					appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 
 				case *ast.ReturnStmt:
 					// function signature states interface return, but the actual return is a struct
@@ -172,7 +180,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 							if i < len(funcSigResultsList) {
 								lhsType := util.TypeOf(pass, funcSigResultsList[i].Type)
 								rhsType := util.TypeOf(pass, node.Results[i])
-								appendTypeToTypeTriggers(lhsType, rhsType)
+								appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 							}
 						}
 					}
@@ -186,7 +194,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 						//  Tracked in issue #46.
 						lhsType := util.TypeOf(pass, nodeType.Elt)
 						for _, elt := range node.Elts {
-							appendTypeToTypeTriggers(lhsType, util.TypeOf(pass, elt))
+							appendTypeToTypeTriggers(node.Pos(), lhsType, util.TypeOf(pass, elt))
 						}
 					case *ast.MapType:
 						// Key, value, or both of a map declared of type interface, and initialized with a struct
@@ -195,8 +203,8 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 						valueType := util.TypeOf(pass, nodeType.Value)
 						for _, elt := range node.Elts {
 							if kv, ok := elt.(*ast.KeyValueExpr); ok {
-								appendTypeToTypeTriggers(keyType, util.TypeOf(pass, kv.Key))
-								appendTypeToTypeTriggers(valueType, util.TypeOf(pass, kv.Value))
+								appendTypeToTypeTriggers(node.Pos(), keyType, util.TypeOf(pass, kv.Key))
+								appendTypeToTypeTriggers(node.Pos(), valueType, util.TypeOf(pass, kv.Value))
 							}
 						}
 					case *ast.Ident:
@@ -218,7 +226,7 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 								}
 							}
 							if lhsType != nil && rhsType != nil {
-								appendTypeToTypeTriggers(lhsType, rhsType)
+								appendTypeToTypeTriggers(node.Pos(), lhsType, rhsType)
 							}
 						}
 					}
@@ -234,8 +242,9 @@ func (a *Affiliation) computeTriggersForCastingSites(pass *analysis.Pass, upstre
 	}
 }
 
-// computeTriggersForTypes finds corresponding concrete implementation and their declared methods and populates them in a map
-func (a *Affiliation) computeTriggersForTypes(lhsType types.Type, rhsType types.Type, upstreamCache ImplementedDeclaredTypesCache, currentCache ImplementedDeclaredTypesCache) []annotation.FullTrigger {
+// computeTriggersForTypes finds corresponding concrete implementation and their declared methods and populates them in a map,
+// where pos is the position of the cast site for reporting
+func (a *Affiliation) computeTriggersForTypes(pass *analysis.Pass, pos token.Pos, lhsType types.Type, rhsType types.Type, upstreamCache ImplementedDeclaredTypesCache, currentCache ImplementedDeclaredTypesCache) []annotation.FullTrigger {
 	if lhsType == nil || rhsType == nil {
 		return nil
 	}
@@ -271,11 +280,39 @@ func (a *Affiliation) computeTriggersForTypes(lhsType types.Type, rhsType types.
 		}
 		if implementedMethod, ok := implementedMethodObj.(*types.Func); ok {
 			triggers = append(triggers, createFunctionTriggers(implementedMethod, interfaceMethod)...)
+			a.checkContracts(pass, pos, implementedMethod, interfaceMethod)
 		}
 	}
 	return triggers
 }
 
+// checkContracts reports the implementing method if it does not redeclare all the contracts
+// (hand-written or inferred) of the interface method, since the contracts of the interface method
+// are trusted at the call sites via the interface. The rule is deliberately strict: the contracts
+// are compared as declared and are not checked against the body of the implementing method, so an
+// implementing method that happens to satisfy a contract (e.g., by never returning nil) is still
+// reported unless it declares the contract as well. The diagnostic is reported at the implementing
+// method if it is declared in the current package, otherwise at the cast site.
+func (a *Affiliation) checkContracts(pass *analysis.Pass, pos token.Pos, implementingMethod *types.Func, interfaceMethod *types.Func) {
+	implContracts := make(map[string]bool)
+	for _, contract := range a.contracts[implementingMethod] {
+		implContracts[contract.String()] = true
+	}
+	for _, contract := range a.contracts[interfaceMethod] {
+		if implContracts[contract.String()] {
+			continue
+		}
+		if implementingMethod.Pkg() == pass.Pkg {
+			pos = implementingMethod.Pos()
+		}
+		a.diagnostics = append(a.diagnostics, analysis.Diagnostic{
+			Pos: pos,
+			Message: fmt.Sprintf("Method `%s` does not redeclare `%s` of the interface method `%s` it implements",
+				util.PartiallyQualifiedFuncName(implementingMethod), contract, util.PartiallyQualifiedFuncName(interfaceMethod)),
+		})
+	}
+}
+
 func getFullyQualifiedName(t types.Type) string {
 	s := ""
 	switch n := t.(type) {
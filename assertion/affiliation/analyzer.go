@@ -18,8 +18,10 @@ import (
 	"fmt"
 	"reflect"
 	"runtime/debug"
+	"time"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function/functioncontracts"
 	"go.uber.org/nilaway/config"
 	"golang.org/x/tools/go/analysis"
 )
@@ -32,10 +34,16 @@ const _doc = "Track interface declarations and their implementations by identify
 type Result struct {
 	// FullTriggers is the slice of full triggers generated from the assertion analysis.
 	FullTriggers []annotation.FullTrigger
+	// Diagnostics is the slice of diagnostics for the implementations not satisfying the function
+	// contracts of the interface methods they implement, which are reported by the upper-level
+	// analyzers.
+	Diagnostics []analysis.Diagnostic
 	// Errors is the slice of errors if errors happened during analysis. We put the errors here as
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
 	// to do with them.
 	Errors []error
+	// Duration is the wall-clock time spent in the analyzer.
+	Duration time.Duration
 }
 
 // Analyzer here is the analyzer that tracks interface implementations and analyzes for nilability
@@ -47,7 +55,7 @@ var Analyzer = &analysis.Analyzer{
 	Run:        run,
 	FactTypes:  []analysis.Fact{new(AffliliationCache)},
 	ResultType: reflect.TypeOf((*Result)(nil)).Elem(),
-	Requires:   []*analysis.Analyzer{config.Analyzer},
+	Requires:   []*analysis.Analyzer{config.Analyzer, functioncontracts.Analyzer},
 }
 
 func run(pass *analysis.Pass) (result interface{}, _ error) {
@@ -66,16 +74,20 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		}
 	}()
 
+	start := time.Now()
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 
 	if !conf.IsPkgInScope(pass.Pkg) {
 		return Result{}, nil
 	}
 
-	a := &Affiliation{conf: conf}
+	a := &Affiliation{
+		conf:      conf,
+		contracts: pass.ResultOf[functioncontracts.Analyzer].(functioncontracts.Result).FunctionContracts,
+	}
 	// get all affiliations
 	a.extractAffiliations(pass)
 
 	// collect all full triggers
-	return Result{FullTriggers: a.triggers}, nil
+	return Result{FullTriggers: a.triggers, Diagnostics: a.diagnostics, Duration: time.Since(start)}, nil
 }
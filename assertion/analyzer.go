@@ -41,6 +41,12 @@ type Result struct {
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
 	// to do with them.
 	Errors []error
+	// SkippedFuncs is the slice of functions skipped by the analysis since they exceed the
+	// configured size limits.
+	SkippedFuncs []function.SkippedFunc
+	// IncompleteFuncs is the slice of functions abandoned during the analysis since they exceed
+	// the configured analysis budget.
+	IncompleteFuncs []function.IncompleteFunc
 }
 
 // Analyzer here is the analyzer than generates assertions and passes them onto the accumulator to
@@ -90,5 +96,10 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		errs = append(errs, resultErrs...)
 	}
 
-	return Result{FullTriggers: triggers, Errors: errs}, nil
+	return Result{
+		FullTriggers:    triggers,
+		Errors:          errs,
+		SkippedFuncs:    r1.SkippedFuncs,
+		IncompleteFuncs: r1.IncompleteFuncs,
+	}, nil
 }
@@ -24,6 +24,7 @@ import (
 	"strconv"
 
 	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
 )
 
@@ -43,7 +44,8 @@ var Analyzer = &analysis.Analyzer{
 type Result struct {
 	// FuncLitMap maps each func lit node to a FuncLitInfo struct storing auxiliary information
 	// our analyzer gathered. This field will always be nonnil even if anonymous function support
-	// is off (in which case an empty map will be set).
+	// is off (in which case only the function literals called, spawned as goroutines or deferred
+	// are collected).
 	FuncLitMap map[*ast.FuncLit]*FuncLitInfo
 	// Errors is the slice of errors if errors happened during analysis. We put the errors here as
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
@@ -105,17 +107,42 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 	funcLitMap := make(map[*ast.FuncLit]*FuncLitInfo)
 
 	for _, file := range pass.Files {
-		if !conf.IsFileInScope(file) || !conf.ExperimentalAnonymousFuncEnable {
+		if !conf.IsFileInScope(file) {
 			continue
 		}
 
 		// Search for top-level function literal declarations across all declarations in a file and call
-		// collectClosure on that, any further recursions will happen in collectClosure
+		// collectClosure on that, any further recursions will happen in collectClosure. If the
+		// experimental anonymous function support is disabled, only the function literals called
+		// by the function declarations (synchronously, spawned as goroutines or deferred), either
+		// directly or via the variables they are assigned to (and the ones nested in them), are
+		// collected, since their bodies are otherwise never analyzed.
 		closureMap := make(map[*ast.FuncLit][]*VarInfo)
 		ast.Inspect(file, func(node ast.Node) bool {
-			if n, ok := node.(*ast.FuncLit); ok {
-				collectClosure(n, pass, closureMap)
+			switch n := node.(type) {
+			case *ast.FuncLit:
+				if conf.ExperimentalAnonymousFuncEnable {
+					collectClosure(n, pass, closureMap)
+				}
 				return false
+			case *ast.CallExpr:
+				if conf.ExperimentalAnonymousFuncEnable {
+					return true
+				}
+				var funcLit *ast.FuncLit
+				switch fun := util.StripParens(n.Fun).(type) {
+				case *ast.FuncLit:
+					funcLit = fun
+				case *ast.Ident:
+					// the function literals called via the variables they are assigned to (e.g.,
+					// `f := func() {...}; f()` or `go f()`) are collected as well
+					funcLit = util.FuncLitFromAssignment(fun)
+				}
+				if funcLit != nil {
+					if _, ok := closureMap[funcLit]; !ok {
+						collectClosure(funcLit, pass, closureMap)
+					}
+				}
 			}
 			return true
 		})
@@ -20,6 +20,7 @@ import (
 	"go/types"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
 )
 
@@ -30,7 +31,10 @@ import (
 // ast.Inspect uses depth-first search, the innermost function literal will be analyzed first. The
 // collected closure variables will also be appended to those of the enclosing function literals,
 // modulo the ones defined in the scope of the enclosing function literals.
-// (2) If the node is an ident node that represents a variable which is not global, it updates the
+// (2) If the node is a call to a function literal via the variable it is assigned to (e.g., `f()`
+// for `f := func() {...}`), the closure variables of the called function literal are appended as
+// well (modulo the ones defined in the current scope), since they are passed at the call site.
+// (3) If the node is an ident node that represents a variable which is not global, it updates the
 // closure set if the node doesn't exist in the current scope.
 func collectClosure(funcLit *ast.FuncLit, pass *analysis.Pass, closureMap map[*ast.FuncLit][]*VarInfo) {
 	// Retrieve the scope of the given function literal
@@ -38,6 +42,27 @@ func collectClosure(funcLit *ast.FuncLit, pass *analysis.Pass, closureMap map[*a
 
 	var varsFromClosure []*VarInfo
 	visited := make(map[*types.Var]bool)
+	// Mark the function literal as being collected, such that the function literals calling each
+	// other via variables (see below) do not recur indefinitely.
+	closureMap[funcLit] = nil
+
+	// addClosureVarsOf adds the closure variables of the other function literal that are not
+	// defined in the current scope.
+	addClosureVarsOf := func(other *ast.FuncLit) {
+		for _, closureVar := range closureMap[other] {
+			obj, ok := pass.TypesInfo.ObjectOf(closureVar.Ident).(*types.Var)
+			if !ok {
+				panic(fmt.Sprintf("identifier %s passed as a variable could not be looked up as one", closureVar.Ident))
+			}
+
+			// Update varsFromClosure with ident if it does not exist in the current scope
+			if scope.Lookup(obj.Name()) != obj && !visited[obj] {
+				varsFromClosure = append(varsFromClosure, closureVar)
+				visited[obj] = true
+			}
+		}
+	}
+
 	ast.Inspect(funcLit.Body, func(n ast.Node) bool {
 		switch node := n.(type) {
 		// closureVar variables required by inner function literals are also required by the current
@@ -48,23 +73,27 @@ func collectClosure(funcLit *ast.FuncLit, pass *analysis.Pass, closureMap map[*a
 			// Any outer closureVar variables that the nested function literals use should also be
 			// required by the current function, so we do a post-processing here to add those
 			// variables.
-			for _, closureVar := range closureMap[node] {
-				obj, ok := pass.TypesInfo.ObjectOf(closureVar.Ident).(*types.Var)
-				if !ok {
-					panic(fmt.Sprintf("identifier %s passed as a variable could not be looked up as one", closureVar.Ident))
-				}
-
-				// Update varsFromClosure with ident if it does not exist in the current scope
-				if scope.Lookup(obj.Name()) != obj {
-					varsFromClosure = append(varsFromClosure, closureVar)
-					visited[obj] = true
-				}
-			}
+			addClosureVarsOf(node)
 
 			// Stop the recursion of ast.Inspect since further recursion was already handled by the
 			// recursive call to collectClosure above.
 			return false
 
+		case *ast.CallExpr:
+			// closureVar variables required by the function literals called via variables are
+			// passed at the call sites, hence they are also required by the current function
+			// literal if they are from outer closures.
+			ident, ok := util.StripParens(node.Fun).(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if callee := util.FuncLitFromAssignment(ident); callee != nil {
+				if _, ok := closureMap[callee]; !ok {
+					collectClosure(callee, pass, closureMap)
+				}
+				addClosureVarsOf(callee)
+			}
+
 		case *ast.Ident:
 			// Skip if node is not a variable
 			if node.Obj == nil || node.Obj.Kind != ast.Var {
@@ -17,18 +17,23 @@ package function
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
 	"reflect"
 	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/assertion/function/assertiontree"
 	"go.uber.org/nilaway/assertion/function/functioncontracts"
+	"go.uber.org/nilaway/assertion/function/noreturn"
 	"go.uber.org/nilaway/assertion/structfield"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
@@ -49,6 +54,32 @@ type Result struct {
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
 	// to do with them.
 	Errors []error
+	// SkippedFuncs is the slice of functions skipped by the analysis since they exceed the
+	// configured size limits.
+	SkippedFuncs []SkippedFunc
+	// IncompleteFuncs is the slice of functions abandoned during the analysis since they exceed
+	// the configured analysis budget. Unlike Errors, they do not prevent the rest of the package
+	// from being analyzed.
+	IncompleteFuncs []IncompleteFunc
+	// FuncStats is the slice of the statistics of analyzing the functions, in the order of the
+	// functions in the package.
+	FuncStats []FuncStats
+	// Duration is the wall-clock time spent in the analyzer.
+	Duration time.Duration
+}
+
+// SkippedFunc is a function skipped by the analysis since it exceeds the size limits configured by
+// config.MaxFuncSizeFlag or config.MaxFuncBlocksFlag.
+type SkippedFunc struct {
+	// Pos is the position of the function.
+	Pos token.Pos
+	// Name is the (partially qualified) name of the function, or "function literal" for a
+	// function literal.
+	Name string
+	// SizeInBytes is the size of the function body in bytes.
+	SizeInBytes int
+	// NumBlocks is the number of blocks in the CFG of the function.
+	NumBlocks int
 }
 
 // Analyzer here is the analyzer than generates assertions and passes them onto the accumulator to
@@ -64,14 +95,37 @@ var Analyzer = &analysis.Analyzer{
 		structfield.Analyzer,
 		anonymousfunc.Analyzer,
 		functioncontracts.Analyzer,
+		noreturn.Analyzer,
 	},
 }
 
-// This limit is in place to prevent the expensive assertions analyzer from being run on
-// overly-sized functions. A possible alternative to this is capping on size of CFG in nodes
-// instead.
-// TODO: test how often (if ever) this is hit
-const _maxFuncSizeInBytes = 10000
+// IncompleteFunc is a function abandoned during the analysis since it exceeds the budget
+// configured by config.FuncTimeoutFlag or config.MaxBackpropRoundsFlag.
+type IncompleteFunc struct {
+	// Pos is the position of the function.
+	Pos token.Pos
+	// Name is the (partially qualified) name of the function, or "function literal" for a
+	// function literal.
+	Name string
+	// Reason describes the exceeded budget.
+	Reason string
+}
+
+// FuncStats are the statistics of analyzing a function, collected for investigating the
+// performance of the analysis.
+type FuncStats struct {
+	// Pos is the position of the function.
+	Pos token.Pos
+	// Name is the (partially qualified) name of the function, or "function literal" for a
+	// function literal.
+	Name string
+	// Duration is the wall-clock time spent in analyzing the function.
+	Duration time.Duration
+	// NumFullTriggers is the number of full triggers generated from the function.
+	NumFullTriggers int
+
+	assertiontree.BackpropStats
+}
 
 // functionResult is the struct that stores the results for analyzing a function declaration.
 type functionResult struct {
@@ -79,15 +133,12 @@ type functionResult struct {
 	triggers []annotation.FullTrigger
 	// err stores any error occurred during the analysis.
 	err error
-	// index is the index of the function declaration in the package. This is particularly
-	// important since currently we have hidden coupling in NilAway that requires the generated
-	// triggers be placed in order of their declarations. Here, the index will ensure that we can
-	// place the triggers in their original order, even though the analyses of function
-	// declarations can be parallelized.
-	// TODO: remove this.
-	index int
 	// funcDecl is the function declaration itself.
 	funcDecl *ast.FuncDecl
+	// duration is the wall-clock time spent in analyzing the function.
+	duration time.Duration
+	// stats are the statistics of the backpropagation across the function.
+	stats assertiontree.BackpropStats
 }
 
 func run(pass *analysis.Pass) (result interface{}, _ error) {
@@ -106,6 +157,7 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		}
 	}()
 
+	start := time.Now()
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 	if !conf.IsPkgInScope(pass.Pkg) {
 		return Result{}, nil
@@ -120,10 +172,12 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		functionConfig.EnableStructInitCheck = conf.ExperimentalStructInitEnable
 		functionConfig.EnableAnonymousFunc = conf.ExperimentalAnonymousFuncEnable
 	}
+	functionConfig.MaxBackpropRounds = conf.MaxBackpropRounds()
 
 	ctrlflowResult := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
 	funcLitMap := pass.ResultOf[anonymousfunc.Analyzer].(anonymousfunc.Result).FuncLitMap
 	funcContracts := pass.ResultOf[functioncontracts.Analyzer].(functioncontracts.Result).FunctionContracts
+	noReturnCalls := pass.ResultOf[noreturn.Analyzer].(noreturn.Result).Calls
 
 	// Create a fake ident map for the fake func decl nodes to be shared for all function contexts.
 	pkgFakeIdentMap := make(map[*ast.Ident]types.Object)
@@ -136,9 +190,7 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 	defer cancel()
 	var wg sync.WaitGroup
 	funcChan := make(chan functionResult)
-	// We use this to keep track of the index of the function declaration we are analyzing.
-	// TODO: remove this once  is done.
-	var funcIndex int
+	var skippedFuncs []SkippedFunc
 	for _, file := range pass.Files {
 		// Skip if a file is marked to be ignored, or it is not in scope of our analysis.
 		if !conf.IsFileInScope(file) {
@@ -146,25 +198,22 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		}
 
 		// Collect all function declarations and function literals if anonymous function support
-		// is enabled.
+		// is enabled, or only the function literals called, spawned as goroutines or deferred
+		// otherwise (see anonymousfunc.Result).
 		var funcs []ast.Node
 		for _, decl := range file.Decls {
 			if f, ok := decl.(*ast.FuncDecl); ok {
 				funcs = append(funcs, f)
 			}
 		}
-		if functionConfig.EnableAnonymousFunc {
-			// We need a stable order of triggers for inference. However, the
-			// fake func decl nodes generated from the anonymous function analyzer are stored in
-			// a map. Hence, here we traverse the file and append the fake func decl nodes in
-			// depth-first order.
-			ast.Inspect(file, func(node ast.Node) bool {
-				if f, ok := node.(*ast.FuncLit); ok {
+		ast.Inspect(file, func(node ast.Node) bool {
+			if f, ok := node.(*ast.FuncLit); ok {
+				if _, collected := funcLitMap[f]; collected || functionConfig.EnableAnonymousFunc {
 					funcs = append(funcs, f)
 				}
-				return true
-			})
-		}
+			}
+			return true
+		})
 
 		for _, fun := range funcs {
 			// Retrieve the auxiliary information about a function to be analyzed, since it is
@@ -192,18 +241,18 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 			if funcDecl.Body == nil {
 				continue
 			}
-			// Skip if the function is too large.
-			funcSizeInBytes := int(funcDecl.Body.Rbrace - funcDecl.Body.Lbrace)
-			if funcSizeInBytes > _maxFuncSizeInBytes {
+			// Skip if the function is too large, which prevents the expensive assertion analysis
+			// from being run on overly-sized functions.
+			if skipped, ok := exceedsSizeLimits(pass, conf, fun, funcDecl, graph); ok {
+				skippedFuncs = append(skippedFuncs, skipped)
 				continue
 			}
 
 			// Now, analyze the function declarations concurrently.
 			wg.Add(1)
 			funcContext := assertiontree.NewFunctionContext(
-				pass, funcDecl, funcLit, functionConfig, funcLitMap, pkgFakeIdentMap, funcContracts)
-			go analyzeFunc(ctx, pass, funcDecl, funcContext, graph, funcIndex, funcChan, &wg)
-			funcIndex++
+				pass, funcDecl, funcLit, functionConfig, funcLitMap, pkgFakeIdentMap, funcContracts, noReturnCalls)
+			go analyzeFunc(ctx, pass, funcDecl, funcContext, graph, conf.FuncTimeout(), funcChan, &wg)
 		}
 	}
 
@@ -214,23 +263,43 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		close(funcChan)
 	}()
 
-	// Now we collect the results for each function analysis. Note that due to hidden couplings in
-	// NilAway, the order of the triggers must align with the order of the function declarations (
-	// as if the analyses were done serially). So we first store the result triggers in order,
-	// then flatten the slice.
-	// TODO: remove this extra logic once  is done.
-	var errs []error
-	funcTriggers := make([][]annotation.FullTrigger, funcIndex)
-	triggerCount := 0
+	// Now we collect the results for each function analysis as they arrive. The order of the
+	// triggers does not matter, since the inference engine observes them in a canonical order.
+	var (
+		triggers        []annotation.FullTrigger
+		errs            []error
+		incompleteFuncs []IncompleteFunc
+		funcStats       []FuncStats
+	)
 	funcResults := map[*types.Func]*functionResult{}
 	for r := range funcChan {
-		if r.err != nil {
+		funcStats = append(funcStats, FuncStats{
+			Pos:             r.funcDecl.Name.Pos(),
+			Name:            funcName(pass, r.funcDecl),
+			Duration:        r.duration,
+			NumFullTriggers: len(r.triggers),
+			BackpropStats:   r.stats,
+		})
+
+		// The functions exceeding the analysis budget are abandoned (i.e., no triggers are
+		// generated for them) without failing the analysis of the entire package.
+		if reason := budgetExceededReason(conf, r.err); reason != "" {
+			incompleteFuncs = append(incompleteFuncs, IncompleteFunc{
+				Pos:    r.funcDecl.Name.Pos(),
+				Name:   funcName(pass, r.funcDecl),
+				Reason: reason,
+			})
+		} else if r.err != nil {
 			errs = append(errs, r.err)
 		} else {
-			funcTriggers[r.index] = r.triggers
-			triggerCount += len(r.triggers)
+			triggers = append(triggers, r.triggers...)
 
-			funcObj, ok := pass.TypesInfo.ObjectOf(r.funcDecl.Name).(*types.Func)
+			// The fake func decls of the function literals are not known to the type checker.
+			obj := pass.TypesInfo.ObjectOf(r.funcDecl.Name)
+			if obj == nil {
+				obj = pkgFakeIdentMap[r.funcDecl.Name]
+			}
+			funcObj, ok := obj.(*types.Func)
 			if !ok {
 				continue
 			}
@@ -241,20 +310,67 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 
 	// Duplicate triggers in contracted functions in the callers of the function
 	if len(funcContracts) != 0 {
-		duplicateFullTriggersFromContractedFunctionsToCallers(pass, funcContracts, funcTriggers,
-			funcResults)
+		triggers = append(triggers, duplicateFullTriggersFromContractedFunctionsToCallers(
+			pass, funcContracts, funcLitMap, funcResults)...)
+	}
+	// The results arrive in the order the analyses finish, so we sort the statistics for
+	// deterministic output.
+	sort.Slice(funcStats, func(i, j int) bool { return funcStats[i].Pos < funcStats[j].Pos })
+
+	return Result{
+		FullTriggers:    triggers,
+		Errors:          errs,
+		SkippedFuncs:    skippedFuncs,
+		IncompleteFuncs: incompleteFuncs,
+		FuncStats:       funcStats,
+		Duration:        time.Since(start),
+	}, nil
+}
+
+// funcName returns the partially qualified name of the function declaration, or "function
+// literal" for the fake function declaration of a function literal.
+func funcName(pass *analysis.Pass, funcDecl *ast.FuncDecl) string {
+	// The fake func decls of the function literals are not known to the type checker.
+	if funcObj, ok := pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func); ok {
+		return util.PartiallyQualifiedFuncName(funcObj)
+	}
+	return "function literal"
+}
+
+// budgetExceededReason returns the description of the exceeded analysis budget if the error is
+// caused by exceeding it, and an empty string otherwise.
+func budgetExceededReason(conf *config.Config, err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Sprintf("exceeded the time budget of %s (%s)", conf.FuncTimeout(), config.FuncTimeoutFlag)
+	case errors.Is(err, assertiontree.ErrBudgetExceeded):
+		return fmt.Sprintf("exceeded the budget of %d backpropagation rounds (%s)", conf.MaxBackpropRounds(),
+			config.MaxBackpropRoundsFlag)
 	}
+	return ""
+}
 
-	// Flatten the triggers
-	triggers := make([]annotation.FullTrigger, 0, triggerCount)
-	for _, s := range funcTriggers {
-		triggers = append(triggers, s...)
+// exceedsSizeLimits returns the information of the function and true if the function (a function
+// declaration or literal, with the given (fake) declaration and CFG) exceeds the size limits in
+// the config, and false otherwise.
+func exceedsSizeLimits(pass *analysis.Pass, conf *config.Config, fun ast.Node, funcDecl *ast.FuncDecl, graph *cfg.CFG) (SkippedFunc, bool) {
+	skipped := SkippedFunc{
+		Pos:         fun.Pos(),
+		Name:        funcName(pass, funcDecl),
+		SizeInBytes: int(funcDecl.Body.Rbrace - funcDecl.Body.Lbrace),
+	}
+	if graph != nil {
+		skipped.NumBlocks = len(graph.Blocks)
 	}
 
-	return Result{FullTriggers: triggers, Errors: errs}, nil
+	maxSize, maxBlocks := conf.MaxFuncSizeInBytes(), conf.MaxFuncBlocks()
+	exceeds := (maxSize > 0 && skipped.SizeInBytes > maxSize) || (maxBlocks > 0 && skipped.NumBlocks > maxBlocks)
+	return skipped, exceeds
 }
 
-// duplicateFullTriggersFromContractedFunctionsToCallers duplicates all the full triggers that have
+// duplicateFullTriggersFromContractedFunctionsToCallers returns the duplicates of all the full triggers that have
 // FuncParam producer or UseAsReturn consumer or both, from the contracted functions to the callers
 // of all the contracted functions. This is necessary because we have created new
 // producers/consumers for argument pass or result return at every call site of the contracted
@@ -264,15 +380,15 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 func duplicateFullTriggersFromContractedFunctionsToCallers(
 	pass *analysis.Pass,
 	funcContracts functioncontracts.Map,
-	funcTriggers [][]annotation.FullTrigger,
+	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo,
 	funcResults map[*types.Func]*functionResult,
-) {
+) []annotation.FullTrigger {
 
 	// Find all the calls to contracted functions
 	// callsByCtrtFunc is a mapping: contracted function -> caller -> all the call expressions
 	callsByCtrtFunc := map[*types.Func]map[*types.Func][]*ast.CallExpr{}
 	for funcObj, r := range funcResults {
-		for ctrFunc, calls := range findCallsToContractedFunctions(r.funcDecl, pass, funcContracts) {
+		for ctrFunc, calls := range findCallsToContractedFunctions(r.funcDecl, pass, funcContracts, funcLitMap) {
 			for _, call := range calls {
 				// TODO: Ideally, we should do
 				//
@@ -295,13 +411,20 @@ func duplicateFullTriggersFromContractedFunctionsToCallers(
 
 	// For every contracted function, duplicate some of its full triggers (that involves param or
 	// return) into all the callers
-	dupTriggers := map[*types.Func][]annotation.FullTrigger{}
+	var dupTriggers []annotation.FullTrigger
 	for ctrtFunc, calls := range callsByCtrtFunc {
+		// The full triggers of the contracted functions without analyzed bodies, i.e., the
+		// functions from upstream packages (whose contracts are imported as facts) and the
+		// interface methods, are not available, so we create the summarizing full triggers at the
+		// call sites instead.
 		r := funcResults[ctrtFunc]
 		if r == nil {
-			// should not happen since funcResults should contain all the functions including any
-			// contracted functions.
-			panic(fmt.Sprintf("Did not find the contracted function %s in funcResults", ctrtFunc.Id()))
+			for _, callExprs := range calls {
+				for _, callExpr := range callExprs {
+					dupTriggers = append(dupTriggers, summarizedContractTriggers(ctrtFunc, callExpr, pass)...)
+				}
+			}
+			continue
 		}
 		for _, trigger := range r.triggers {
 			// If the full trigger has a FuncParam producer or a UseAsReturn consumer, then create
@@ -314,28 +437,19 @@ func duplicateFullTriggersFromContractedFunctionsToCallers(
 				continue
 			}
 			// Duplicate the full trigger in every caller
-			for caller, callExprs := range calls {
+			for _, callExprs := range calls {
 				for _, callExpr := range callExprs {
 					dupTrigger := duplicateFullTrigger(trigger, ctrtFunc, callExpr, pass,
 						isParamProducer, isReturnConsumer)
 
 					// Store the duplicated full trigger
-					dupTriggers[caller] = append(dupTriggers[caller], dupTrigger)
+					dupTriggers = append(dupTriggers, dupTrigger)
 				}
 			}
 		}
 	}
 
-	// Update funcTriggers with duplicated triggers
-	for funcObj, triggers := range dupTriggers {
-		r := funcResults[funcObj]
-		if r == nil {
-			// should not happen since funcResults should contain all the functions including any
-			// contracted functions.
-			panic(fmt.Sprintf("Did not find the contracted function %s in funcResults", funcObj.Id()))
-		}
-		funcTriggers[r.index] = append(funcTriggers[r.index], triggers...)
-	}
+	return dupTriggers
 }
 
 // duplicateFullTrigger creates a (possibly controlled) full trigger from the given full trigger
@@ -350,7 +464,7 @@ func duplicateFullTrigger(
 	isReturnConsumer bool,
 ) annotation.FullTrigger {
 	// TODO: what if we have more than one parameter, planned in future revisions
-	argExpr := callExpr.Args[0]
+	argExpr := contractedArg(callExpr, pass)
 	argLoc := util.PosToLocation(argExpr.Pos(), pass)
 
 	// Create the duplicated full trigger
@@ -383,6 +497,66 @@ func duplicateFullTrigger(
 	return dupTrigger
 }
 
+// summarizedContractTriggers creates the full triggers at the call site of a function with
+// contract `contract(nonnil -> nonnil)` whose body is not analyzed (e.g., a function from an
+// upstream package or an interface method), where the full triggers of the function body are not
+// available. Instead, the function is summarized by the following full triggers:
+//   - the argument flows to the param of the function declaration, such that the requirements of
+//     the function on the param (e.g., the param being inferred nonnil due to a dereference in the
+//     function body) are still checked at the call site;
+//   - the argument flows to the result at the call site, controlled by the argument at the call
+//     site, i.e., the result is nilable only if the argument is nilable, which is exactly what the
+//     contract promises.
+func summarizedContractTriggers(callee *types.Func, callExpr *ast.CallExpr, pass *analysis.Pass) []annotation.FullTrigger {
+	argExpr := contractedArg(callExpr, pass)
+	argLoc := util.PosToLocation(argExpr.Pos(), pass)
+	paramKey := annotation.NewCallSiteParamKey(callee, 0, argLoc)
+	argProducer := func() *annotation.ProduceTrigger {
+		return &annotation.ProduceTrigger{
+			Annotation: &annotation.FuncParam{TriggerIfNilable: &annotation.TriggerIfNilable{Ann: paramKey}},
+			Expr:       argExpr,
+		}
+	}
+
+	return []annotation.FullTrigger{
+		{
+			Producer: argProducer(),
+			Consumer: &annotation.ConsumeTrigger{
+				Annotation: &annotation.ArgPass{TriggerIfNonNil: &annotation.TriggerIfNonNil{
+					Ann: annotation.ParamKeyFromArgNum(callee, 0),
+				}},
+				Expr:   argExpr,
+				Guards: util.NoGuards(),
+			},
+			CreatedFromDuplication: true,
+		},
+		{
+			Producer: argProducer(),
+			Consumer: &annotation.ConsumeTrigger{
+				Annotation: &annotation.UseAsReturn{TriggerIfNonNil: &annotation.TriggerIfNonNil{
+					Ann: annotation.NewCallSiteRetKey(callee, 0, util.PosToLocation(callExpr.Pos(), pass)),
+				}},
+				Expr:   callExpr,
+				Guards: util.NoGuards(),
+			},
+			Controller:             paramKey,
+			CreatedFromDuplication: true,
+		},
+	}
+}
+
+// contractedArg returns the argument passed to the only param of the function with contract
+// `contract(nonnil -> nonnil)` at the call site. Note that the receiver is passed as the first
+// argument for the calls via method expressions (e.g., `T.M(recv, x)`), which is skipped here.
+func contractedArg(callExpr *ast.CallExpr, pass *analysis.Pass) ast.Expr {
+	if sel, ok := util.StripParens(callExpr.Fun).(*ast.SelectorExpr); ok {
+		if selection, ok := pass.TypesInfo.Selections[sel]; ok && selection.Kind() == types.MethodExpr {
+			return callExpr.Args[1]
+		}
+	}
+	return callExpr.Args[0]
+}
+
 // findCallsToContractedFunctions finds all the calls to the contracted functions in the given
 // function, and returns a map from every called contracted function to the call expressions that
 // call it.
@@ -390,6 +564,7 @@ func findCallsToContractedFunctions(
 	funcNode *ast.FuncDecl,
 	pass *analysis.Pass,
 	functionContracts functioncontracts.Map,
+	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo,
 ) map[*types.Func][]*ast.CallExpr {
 	calls := map[*types.Func][]*ast.CallExpr{}
 	ast.Inspect(funcNode, func(n ast.Node) bool {
@@ -398,21 +573,15 @@ func findCallsToContractedFunctions(
 			return true
 		}
 
-		ident := util.FuncIdentFromCallExpr(callExpr)
-		if ident == nil {
-			return true
-		}
-
-		funcObj, ok := pass.TypesInfo.ObjectOf(ident).(*types.Func)
-		if !ok {
+		funcObj := assertiontree.CalleeOf(pass, callExpr, funcLitMap)
+		if funcObj == nil {
 			return true
 		}
 
-		// TODO: for now we find the functions with only a single contract nonnil -> nonnil. If we
-		//  want to support multiple contracts or contracts with multiple/other values not only we
-		//  should update here, but we should also make changes to other parts of duplicating
-		//  triggers.
-		if !hasOnlyNonNilToNonNilContract(functionContracts, funcObj) {
+		// Only the functions with a single contract nonnil -> nonnil are applied by duplicating
+		// their triggers, the other contracts are evaluated at the call sites instead (see
+		// RootAssertionNode.getFuncReturnProducers).
+		if !functionContracts.HasOnlyNonNilToNonNil(funcObj) {
 			return true
 		}
 		calls[funcObj] = append(calls[funcObj], callExpr)
@@ -421,29 +590,18 @@ func findCallsToContractedFunctions(
 	return calls
 }
 
-// hasOnlyNonNilToNonNilContract returns whether the given function has only one contract that is
-// nonnil->nonnil.
-func hasOnlyNonNilToNonNilContract(funcContracts functioncontracts.Map, funcObj *types.Func) bool {
-	contracts, ok := funcContracts[funcObj]
-	if !ok || len(contracts) != 1 {
-		return false
-	}
-	ctr := contracts[0]
-	return len(ctr.Ins) == 1 && ctr.Ins[0] == functioncontracts.NonNil &&
-		len(ctr.Outs) == 1 && ctr.Outs[0] == functioncontracts.NonNil
-}
-
 // analyzeFunc analyzes a given function declaration and emit generated triggers, or an error if
 // something went wrong during the analysis. It is mainly a wrapper function for
 // assertiontree.BackpropAcrossFunc with synchronization and communication support for concurrency.
-// The actual result will be sent via the channel.
+// The actual result will be sent via the channel. If timeout is positive, the analysis is
+// cancelled with context.DeadlineExceeded once it runs longer than the timeout.
 func analyzeFunc(
 	ctx context.Context,
 	pass *analysis.Pass,
 	funcDecl *ast.FuncDecl,
 	funcContext assertiontree.FunctionContext,
 	graph *cfg.CFG,
-	index int,
+	timeout time.Duration,
 	funcChan chan functionResult,
 	wg *sync.WaitGroup,
 ) {
@@ -458,12 +616,20 @@ func analyzeFunc(
 	defer func() {
 		if r := recover(); r != nil {
 			e := fmt.Errorf("INTERNAL PANIC: %s\n%s", r, string(debug.Stack()))
-			funcChan <- functionResult{err: e, index: index, funcDecl: funcDecl}
+			funcChan <- functionResult{err: e, funcDecl: funcDecl}
 		}
 	}()
 
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	// Do the actual backpropagation.
-	funcTriggers, err := assertiontree.BackpropAcrossFunc(ctx, pass, funcDecl, funcContext, graph)
+	start := time.Now()
+	funcTriggers, stats, err := assertiontree.BackpropAcrossFunc(ctx, pass, funcDecl, funcContext, graph)
+	duration := time.Since(start)
 
 	// If any error occurs in back-propagating the function, we wrap the error with more information.
 	if err != nil {
@@ -474,7 +640,8 @@ func analyzeFunc(
 	funcChan <- functionResult{
 		triggers: funcTriggers,
 		err:      err,
-		index:    index,
 		funcDecl: funcDecl,
+		duration: duration,
+		stats:    stats,
 	}
 }
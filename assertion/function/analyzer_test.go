@@ -67,8 +67,9 @@ func TestTimeout(t *testing.T) {
 	emptyFuncLitMap := make(map[*ast.FuncLit]*anonymousfunc.FuncLitInfo)
 	emptyPkgFakeIdentMap := make(map[*ast.Ident]types.Object)
 	emptyFuncContracts := make(functioncontracts.Map)
+	emptyNoReturnCalls := make(map[*ast.CallExpr]bool)
 	funcContext := assertiontree.NewFunctionContext(pass, funcDecl, nil, /* funcLit */
-		funcConfig, emptyFuncLitMap, emptyPkgFakeIdentMap, emptyFuncContracts)
+		funcConfig, emptyFuncLitMap, emptyPkgFakeIdentMap, emptyFuncContracts, emptyNoReturnCalls)
 	// (3) Set up synchronization and communication for the goroutine we are going to spawn.
 	resultChan := make(chan functionResult)
 	wg := new(sync.WaitGroup)
@@ -79,7 +80,7 @@ func TestTimeout(t *testing.T) {
 	cancel()
 
 	ctrlflowResult := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
-	go analyzeFunc(ctx, pass, funcDecl, funcContext, ctrlflowResult.FuncDecl(funcDecl), 0, resultChan, wg)
+	go analyzeFunc(ctx, pass, funcDecl, funcContext, ctrlflowResult.FuncDecl(funcDecl), 0 /* timeout */, resultChan, wg)
 
 	// Spawn a goroutine to wait and close the result channel when the work is done.
 	go func() {
@@ -90,7 +91,7 @@ func TestTimeout(t *testing.T) {
 	// Since we have passed a cancelled context, the goroutine should immediately return with a
 	// Canceled error.
 	res := <-resultChan
-	require.Equal(t, res.index, 0)
+	require.Equal(t, res.funcDecl, funcDecl)
 	require.ErrorIs(t, res.err, context.Canceled)
 }
 
@@ -110,7 +111,7 @@ func TestAnalyzeFuncPanic(t *testing.T) {
 		&ast.FuncDecl{},                 /* funcDecl */
 		assertiontree.FunctionContext{}, /* funcContext */
 		&cfg.CFG{},                      /* graph */
-		0,                               /* index */
+		0,                               /* timeout */
 		resultChan,
 		&wg,
 	)
@@ -121,7 +122,6 @@ func TestAnalyzeFuncPanic(t *testing.T) {
 	}()
 
 	res := <-resultChan
-	require.Equal(t, res.index, 0)
 	require.ErrorContains(t, res.err, "panic")
 }
 
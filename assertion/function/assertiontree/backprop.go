@@ -61,8 +61,12 @@ func backpropAcrossNode(rootNode *RootAssertionNode, node ast.Node) error {
 	switch n := util.StripParens(node).(type) {
 
 	case *ast.ReturnStmt:
+		if err := backpropAcrossDeferredCalls(rootNode, n); err != nil {
+			return err
+		}
 		return backpropAcrossReturn(rootNode, n)
 	case *ast.AssignStmt:
+		backpropAcrossGoroutineCaptures(rootNode, n)
 		return backpropAcrossAssignment(rootNode, n.Lhs, n.Rhs)
 	case *ast.ValueSpec:
 		// These nodes represent declarations such as `var x, y : int = 4, 3`
@@ -77,7 +81,12 @@ func backpropAcrossNode(rootNode *RootAssertionNode, node ast.Node) error {
 	case *ast.ExprStmt:
 		rootNode.AddComputation(n.X)
 	case *ast.GoStmt:
+		// The function value and the arguments (including the closure variables of a function
+		// literal) are evaluated at the go statement, while the assignments to the closure
+		// variables afterward are handled by backpropAcrossGoroutineCaptures.
 		rootNode.AddComputation(n.Call)
+	case *ast.DeferStmt:
+		backpropAcrossDefer(rootNode, n)
 	case *ast.IncDecStmt:
 		rootNode.AddComputation(n.X)
 
@@ -103,9 +112,8 @@ func backpropAcrossNode(rootNode *RootAssertionNode, node ast.Node) error {
 		}
 	// The following cases are not interesting to our nilness analysis, or are currently
 	// unsupported, so we do nothing for them.
-	case *ast.BasicLit, *ast.Ident, *ast.EmptyStmt, *ast.DeferStmt:
+	case *ast.BasicLit, *ast.Ident, *ast.EmptyStmt:
 		// TODO: figure out what source code generates these cases - it's not obvious
-		// TODO: handle defers
 	default:
 		return fmt.Errorf("unrecognized AST node %T in CFG - add a case for it", n)
 	}
@@ -119,10 +127,18 @@ func backpropAcrossSend(rootNode *RootAssertionNode, node *ast.SendStmt) error {
 	// Note that for channel sends, we have:
 	// (1) A send to a nil channel blocks forever;
 	// (2) A send to a closed channel panics.
-	// (1) falls out of scope for NilAway and hence we do not create a consumer here for the
-	// channel variable. For (2), since we do not track the state of the channels, we currently
-	// cannot support it.
-	// TODO: rethink our strategy of handling channels (#192).
+	// For (1), we create a consumer for the channel variable, unless the send is a case of a
+	// select statement, where the cases with nil channels are simply never chosen (which is a
+	// common idiom for disabling cases). For (2), since we do not track the state of the
+	// channels, we currently cannot support it.
+	if !rootNode.functionContext.selectSends[node] {
+		rootNode.AddConsumption(&annotation.ConsumeTrigger{
+			Annotation: &annotation.ChanSentTo{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}},
+			Expr:       node.Chan,
+			Guards:     util.NoGuards(),
+		})
+	}
+
 	consumer, err := exprAsAssignmentConsumer(rootNode, node, nil)
 	if err != nil {
 		return err
@@ -141,6 +157,98 @@ func backpropAcrossSend(rootNode *RootAssertionNode, node *ast.SendStmt) error {
 	return nil
 }
 
+// backpropAcrossGoroutineCaptures handles the assignments to the variables captured by the
+// goroutines spawned (on some path) before the assignments. The captured variables are passed to
+// the function literals as arguments at the go statements (see funcArgsFromCallExpr), but the
+// goroutines may run after the assignments as well, so the values assigned are passed to the
+// function literals here in the same way. For example, `x` is still nilable in the goroutine
+// below, even though it is checked before the goroutine is spawned:
+//
+//	if x != nil {
+//		go func() { print(*x) }()
+//	}
+//	x = nil
+func backpropAcrossGoroutineCaptures(rootNode *RootAssertionNode, node *ast.AssignStmt) {
+	for _, info := range rootNode.functionContext.goroutineCaptures[node] {
+		// The closure variables are the trailing params of the fake function.
+		offset := info.FakeFuncObj.Type().(*types.Signature).Params().Len() - len(info.ClosureVars)
+		for _, lhs := range node.Lhs {
+			ident, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			obj := rootNode.ObjectOf(ident)
+			for i, closureVar := range info.ClosureVars {
+				if closureVar.Obj != obj {
+					continue
+				}
+				rootNode.AddConsumption(&annotation.ConsumeTrigger{
+					Annotation: &annotation.ArgPass{
+						TriggerIfNonNil: &annotation.TriggerIfNonNil{
+							Ann: annotation.ParamKeyFromArgNum(info.FakeFuncObj, offset+i),
+						}},
+					Expr:   ident,
+					Guards: util.NoGuards(),
+				})
+			}
+		}
+	}
+}
+
+// backpropAcrossDefer handles backpropagation for defer statements. The function value, the
+// receiver and the arguments of a deferred call are evaluated when the defer statement executes,
+// and that is all we can observe about a call to a declared function or method (whose body is
+// analyzed separately), so such calls are computed right here. Calls to function literals, on the
+// other hand, read the captured variables when they are called at the function exits, hence they
+// are computed at the return statements instead (see backpropAcrossDeferredCalls). The exception
+// is the calls deferred conditionally (e.g., in a branch or a loop), which are computed here as
+// well, such that the captured variables are read at the defer statements: the assignments to
+// them afterward are missed, but the calls are not assumed to be executed on the paths that do
+// not defer them.
+func backpropAcrossDefer(rootNode *RootAssertionNode, node *ast.DeferStmt) {
+	if rootNode.functionContext.deferredAtReturns[node] {
+		return
+	}
+	rootNode.AddComputation(node.Call)
+}
+
+// backpropAcrossDeferredCalls handles backpropagation for the deferred calls to function literals
+// executed when returning via the return statement. Since the deferred calls run after the
+// returned values are assigned to the named results (if any), which they may read, the assignment
+// is modeled here as well. Note that the panicking exits are out of scope, i.e., the deferred calls
+// are not modeled at the calls that may panic, and the assignments to the named results in the
+// deferred calls (e.g., when recovering) do not flow to the returned values.
+func backpropAcrossDeferredCalls(rootNode *RootAssertionNode, node *ast.ReturnStmt) error {
+	calls := rootNode.functionContext.deferredCalls[node]
+	if len(calls) == 0 {
+		return nil
+	}
+	// The calls are executed in the reverse order they are deferred, so we backpropagate across
+	// them in the order they are deferred.
+	for _, call := range calls {
+		rootNode.AddComputation(call)
+	}
+
+	if len(node.Results) == 0 {
+		return nil
+	}
+	var namedResults []ast.Expr
+	if results := rootNode.FuncDecl().Type.Results; results != nil {
+		for _, field := range results.List {
+			if len(field.Names) == 0 {
+				return nil
+			}
+			for _, name := range field.Names {
+				namedResults = append(namedResults, name)
+			}
+		}
+	}
+	if len(namedResults) == 0 {
+		return nil
+	}
+	return backpropAcrossAssignment(rootNode, namedResults, node.Results)
+}
+
 // backpropAcrossReturn handles backpropagation for return statements. It is designed to be called
 // from backpropAcrossNode as a special handler.
 func backpropAcrossReturn(rootNode *RootAssertionNode, node *ast.ReturnStmt) error {
@@ -187,7 +295,7 @@ func backpropAcrossReturn(rootNode *RootAssertionNode, node *ast.ReturnStmt) err
 				// `fident` is actually definitely, non-nil here, tracked as
 				return errors.New("fident variable is nil")
 			}
-			funcObj := rootNode.ObjectOf(fident).(*types.Func)
+			funcObj := rootNode.funcObjOf(fident)
 			if util.FuncNumResults(funcObj) > 1 {
 				// this is the case we were looking for!
 				// we've identified that a multiply-returning function is being returned
@@ -215,7 +323,7 @@ func backpropAcrossReturn(rootNode *RootAssertionNode, node *ast.ReturnStmt) err
 								Annotation: &annotation.UseAsReturn{
 									TriggerIfNonNil: &annotation.TriggerIfNonNil{
 										Ann: annotation.RetKeyFromRetNum(
-											rootNode.ObjectOf(rootNode.FuncNameIdent()).(*types.Func),
+											rootNode.FuncObj(),
 											i,
 										)},
 									RetStmt: node,
@@ -259,6 +367,11 @@ func backpropAcrossAssignment(rootNode *RootAssertionNode, lhs, rhs []ast.Expr)
 		// Now that we've back-propagated across the assignment itself, make sure we can compute
 		// all of the lhs and rhs.
 		for _, rhsVal := range rhs {
+			// The comma-ok form of type assertions (`v, ok := x.(T)`) does not panic if `x` is
+			// nil, so we only compute `x` instead of the type assertion (see AddComputation).
+			if r, ok := util.StripParens(rhsVal).(*ast.TypeAssertExpr); ok && len(lhs) == 2 {
+				rhsVal = r.X
+			}
 			rootNode.AddComputation(rhsVal)
 		}
 		for _, lhsVal := range lhs {
@@ -297,7 +410,7 @@ func backpropAcrossAssignment(rootNode *RootAssertionNode, lhs, rhs []ast.Expr)
 		// currently handle the following cases in NilAway:
 		// 1. Map read: `v, ok := m[k]`
 		// 2. Channel receive: `v, ok := <-ch`
-		// TODO: 3. Type assertion: `v, ok := y.(*type)`
+		// 3. Type assertion: `v, ok := y.(*type)`
 		if len(lhs) == 2 {
 			rootNode.AddGuardMatch(lhs[0], ContinueTracking)
 
@@ -336,8 +449,8 @@ func backpropAcrossAssignment(rootNode *RootAssertionNode, lhs, rhs []ast.Expr)
 
 			// Type assertion
 			if r, ok := rhsNode.(*ast.TypeAssertExpr); ok && r.Type != nil {
-				// TODO: properly handle type assertions' "OK" contract
-				return backpropAcrossOneToOneAssignment(rootNode, lhs[0:1], rhs)
+				rootNode.AddGuardMatch(r.X, ProduceAsNonnil)
+				return backpropAcrossTypeAssertOk(rootNode, lhs[0], r)
 			}
 		}
 	}
@@ -425,6 +538,43 @@ func backpropAcrossRange(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Ex
 	return nil
 }
 
+// backpropAcrossTypeAssertOk handles comma-ok type assertions (e.g., "v, ok := x.(*T)"), it is
+// designed to be called from backpropAcrossAssignment as a finer-grained handler for special
+// assignment cases. If the assertion succeeds, `v` holds the value of `x`, which may still be a
+// nil `*T` wrapped in a non-nil interface, so the consumers of `v` guarded by a check of `ok` are
+// moved to `x` as in a normal assignment. Otherwise, `v` is the zero value of the asserted type
+// (i.e., nil for the nilable types), so the other consumers of `v` are triggered right away.
+func backpropAcrossTypeAssertOk(rootNode *RootAssertionNode, lhs ast.Expr, rhs *ast.TypeAssertExpr) error {
+	if util.IsEmptyExpr(lhs) {
+		return nil
+	}
+
+	lhsPath, _ := rootNode.ParseExprAsProducer(lhs, false)
+	if lhsNode, _ := rootNode.lookupPath(lhsPath); lhsNode != nil {
+		guard, hasGuard := rootNode.GetNonce(lhs)
+		var guarded []*annotation.ConsumeTrigger
+		for _, consumer := range lhsNode.ConsumeTriggers() {
+			// Note that the guarded consumers remain matched (see AddGuardMatch), since a check of
+			// `ok` covers the guards of the producers of `x` as well: e.g., if `x` is read from a
+			// map without a guard, the missing value is a nil interface, which fails the assertion.
+			if hasGuard && consumer.Guards.Contains(guard) {
+				guarded = append(guarded, consumer)
+				continue
+			}
+			rootNode.AddNewTriggers(annotation.FullTrigger{
+				Producer: &annotation.ProduceTrigger{
+					Annotation: &annotation.FailedTypeAssert{ProduceTriggerTautology: &annotation.ProduceTriggerTautology{}},
+					Expr:       lhs,
+				},
+				Consumer: consumer,
+			})
+		}
+		lhsNode.SetConsumeTriggers(guarded)
+	}
+
+	return backpropAcrossOneToOneAssignment(rootNode, []ast.Expr{lhs}, []ast.Expr{rhs.X})
+}
+
 // backpropAcrossTypeSwitch handles type switches (e.g., "switch v := a.(*type)"), it is designed
 // to be called from backpropAcrossAssignment as a finer-grained handler for special assignment
 // cases. The main reason that this case has to be handled separately is that it introduces a
@@ -433,7 +583,9 @@ func backpropAcrossRange(rootNode *RootAssertionNode, lhs []ast.Expr, rhs ast.Ex
 // associated with the declaration site as there usually would be through TypesInfo.Defs, and
 // TypesInfo. Uses will give a fresh `types.Var` at every usage site. This is why we have to
 // inspect the assertion tree for any variables that match the symbolic type switch variable
-// without being able to compare the identity of `types.Var` instances as we usually do.
+// without being able to compare the identity of `types.Var` instances as we usually do. The
+// variables bound by a `case nil` clause are produced as nil, and the others as the switched
+// expression (which may hold a nil pointer even if the case type is a pointer type).
 // nonnil(lhs, rhs)
 func backpropAcrossTypeSwitch(rootNode *RootAssertionNode, lhs *ast.Ident, rhs ast.Expr) error {
 	// First, make a copy of the children array to iterate over, as we will mutate it.
@@ -456,6 +608,15 @@ func backpropAcrossTypeSwitch(rootNode *RootAssertionNode, lhs *ast.Ident, rhs a
 					// this nil check reflects programmer logic
 					return errors.New("liftedChild variable is nil")
 				}
+				if rootNode.functionContext.nilCaseTypeSwitchVars[varChild.decl] {
+					// the symbolic variable is bound by a `case nil` clause, so it is always nil
+					liftedChild.SetParent(rootNode)
+					rootNode.triggerProductions(liftedChild, &annotation.ProduceTrigger{
+						Annotation: &annotation.ConstNil{ProduceTriggerTautology: &annotation.ProduceTriggerTautology{}},
+						Expr:       lhs,
+					})
+					continue
+				}
 				rhsPath, rhsProducers := rootNode.ParseExprAsProducer(rhs, false)
 				if rhsPath != nil {
 					// rhs is trackable, so move assertions as we would in the vanilla assignment case
@@ -801,15 +962,35 @@ func computePostOrder(blocks []*cfg.Block) []int {
 	return postOrder
 }
 
+// ErrBudgetExceeded is returned (wrapped) by BackpropAcrossFunc if the backpropagation does not
+// terminate within the budget configured by FunctionConfig.MaxBackpropRounds.
+var ErrBudgetExceeded = errors.New("analysis budget exceeded")
+
+// BackpropStats are the statistics of the backpropagation across a function, collected for
+// investigating the performance of the analysis.
+type BackpropStats struct {
+	// Rounds is the number of rounds of the backpropagation.
+	Rounds int
+	// MaxTreeSize is the maximum size (see RootAssertionNode.Size) of the assertion tree at entry
+	// to the function over the rounds.
+	MaxTreeSize int
+}
+
 // BackpropAcrossFunc is the main driver of the backpropagation, it takes a function declaration
 // with accompanying CFG, and back-propagates a tree of assertions across it to generate, at entry
-// to the function, the set of assertions that must hold to avoid possible nil flow errors.
+// to the function, the set of assertions that must hold to avoid possible nil flow errors. The
+// statistics of the backpropagation are returned as well, even if it fails.
 func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.FuncDecl,
-	functionContext FunctionContext, graph *cfg.CFG) ([]annotation.FullTrigger, error) {
+	functionContext FunctionContext, graph *cfg.CFG) ([]annotation.FullTrigger, BackpropStats, error) {
 	// We transform the CFG to have it reflect the implicit control flow that happens
 	// inside short-circuiting boolean expressions.
+	functionContext.shortCircuitExprs = make(map[ast.Expr]bool)
 	graph, richCheckBlocks, exprNonceMap := preprocess(graph, functionContext)
 	blocks, preprocessing := blocksAndPreprocessingFromCFG(pass, graph, richCheckBlocks)
+	functionContext.deferredCalls, functionContext.deferredAtReturns = deferredCallsAtReturns(blocks)
+	functionContext.nilCaseTypeSwitchVars = nilCaseTypeSwitchVars(pass, decl.Body)
+	functionContext.selectSends = selectSends(decl.Body)
+	functionContext.goroutineCaptures = goroutineCaptures(blocks, functionContext.funcLitMap)
 
 	// The assertion nodes for each block and an array of bools to indicate whether each block is
 	// updated in this round or not.
@@ -823,6 +1004,7 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 	// We consider the backpropagation stable if # of stable rounds > # of live blocks + tolerance.
 	var currRootAssertionNode, nextRootAssertionNode *RootAssertionNode
 	roundCount, stableRoundCount := 0, 0
+	var stats BackpropStats
 	postOrder := computePostOrder(blocks)
 
 	// Initialize the process by creating the assertion nodes for the return block.
@@ -832,12 +1014,16 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 
 	for slices.Contains(updatedLastRound, true) {
 		roundCount++
+		stats.Rounds = roundCount
 
 		select {
 		case <-ctx.Done():
-			return nil, fmt.Errorf("backprop early stop due to context: %w", ctx.Err())
+			return nil, stats, fmt.Errorf("backprop early stop due to context: %w", ctx.Err())
 		default:
 		}
+		if maxRounds := functionContext.functionConfig.MaxBackpropRounds; maxRounds > 0 && roundCount > maxRounds {
+			return nil, stats, fmt.Errorf("backprop did not terminate in %d rounds: %w", maxRounds, ErrBudgetExceeded)
+		}
 
 		for _, i := range postOrder {
 			block := blocks[i]
@@ -848,7 +1034,7 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 			}
 
 			if len(block.Succs) > 2 {
-				return nil, errors.New("assumptions about CFG shape violated - a block has >2 successors")
+				return nil, stats, errors.New("assumptions about CFG shape violated - a block has >2 successors")
 			}
 
 			// No need to re-process the assertion node for the current block if it does not have
@@ -899,7 +1085,7 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 			// No assertion nodes attached with any successors, this should never happen since we
 			// will only reach here if any of the successors were updated in the current or last round.
 			if len(succs) == 0 {
-				return nil, fmt.Errorf("no assertion nodes for successors of block %d", block.Index)
+				return nil, stats, fmt.Errorf("no assertion nodes for successors of block %d", block.Index)
 			}
 
 			// Merge the branch successors if they are both available.
@@ -911,7 +1097,7 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 			nextAssertions[i] = succs[0]
 			err := backpropAcrossBlock(nextAssertions[i], blocks[i])
 			if err != nil {
-				return nil, err
+				return nil, stats, err
 			}
 
 			// Monotonize updates updatedThisRound to reflect whether the assertions changed at a given index.
@@ -941,6 +1127,9 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 		if nextAssertions[0] != nil {
 			nextRootAssertionNode = CopyNode(nextAssertions[0]).(*RootAssertionNode)
 			nextRootAssertionNode.ProcessEntry()
+			if size := nextRootAssertionNode.Size(); size > stats.MaxTreeSize {
+				stats.MaxTreeSize = size
+			}
 		}
 
 		if nextRootAssertionNode == nil && currRootAssertionNode == nil ||
@@ -976,7 +1165,7 @@ func BackpropAcrossFunc(ctx context.Context, pass *analysis.Pass, decl *ast.Func
 
 	// Return the generated full triggers at the entry block; we're done!
 	if currRootAssertionNode == nil {
-		return nil, nil
+		return nil, stats, nil
 	}
-	return currRootAssertionNode.triggers, nil
+	return currRootAssertionNode.triggers, stats, nil
 }
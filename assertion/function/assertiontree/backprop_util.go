@@ -21,10 +21,14 @@ import (
 	"go/constant"
 	"go/token"
 	"go/types"
+	"sort"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/anonymousfunc"
+	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
 	"go.uber.org/nilaway/util/asthelper"
+	"golang.org/x/exp/slices"
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/cfg"
 )
@@ -147,7 +151,7 @@ func computeAndConsumeResults(rootNode *RootAssertionNode, node *ast.ReturnStmt)
 
 			// below is the normal handling for named return variables
 			for i, retVariable := range results {
-				retKey := annotation.RetKeyFromRetNum(rootNode.ObjectOf(rootNode.FuncNameIdent()).(*types.Func), i)
+				retKey := annotation.RetKeyFromRetNum(rootNode.FuncObj(), i)
 
 				// default handling if retVariable is not a blank identifier (e.g., i *int)
 				if !util.IsEmptyExpr(retVariable) {
@@ -210,7 +214,7 @@ func computeAndConsumeResults(rootNode *RootAssertionNode, node *ast.ReturnStmt)
 
 	// we've excluded all abnormal cases - here, just really consume each result as a return value
 	for i := range node.Results {
-		retKey := annotation.RetKeyFromRetNum(rootNode.ObjectOf(rootNode.FuncNameIdent()).(*types.Func), i)
+		retKey := annotation.RetKeyFromRetNum(rootNode.FuncObj(), i)
 		addReturnConsumers(rootNode, node, node.Results[i], retKey, false /* isNamedReturn */)
 
 		if rootNode.functionContext.functionConfig.EnableStructInitCheck {
@@ -244,7 +248,17 @@ func isErrorReturnNil(rootNode *RootAssertionNode, errRet ast.Expr) bool {
 // isErrorReturnNonnil returns true if the error return is guaranteed to be nonnil, false otherwise
 func isErrorReturnNonnil(rootNode *RootAssertionNode, errRet ast.Expr) bool {
 	t := rootNode.Pass().TypesInfo.TypeOf(errRet)
-	if _, ok := AsTrustedFuncAction(errRet, rootNode.Pass()); ok || util.TypeAsDeeplyStruct(t) != nil {
+	if ret, ok := AsTrustedFuncAction(errRet, rootNode.Pass()); ok {
+		// The result of a trusted function is nonnil, unless it is declared by the users to be nilable.
+		prod, ok := ret.(*annotation.ProduceTrigger)
+		if !ok {
+			return true
+		}
+		if _, nilable := prod.Annotation.(*annotation.TrustedFuncNilable); !nilable {
+			return true
+		}
+	}
+	if util.TypeAsDeeplyStruct(t) != nil {
 		return true
 	}
 
@@ -441,7 +455,7 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 	}
 
 	handleAssignmentToIdent := func(ident *ast.Ident) annotation.ConsumingAnnotationTrigger {
-		v := rootNode.ObjectOf(ident).(*types.Var)
+		v := rootNode.varObjOf(ident)
 		if annotation.VarIsGlobal(v) {
 			// we've found an assignment to a global
 			return &annotation.GlobalVarAssign{
@@ -456,7 +470,7 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 	handleDeepAssignmentToIdent :=
 		func(ident *ast.Ident) annotation.ConsumingAnnotationTrigger {
 			funcObj := rootNode.FuncObj()
-			varObj := rootNode.ObjectOf(ident).(*types.Var)
+			varObj := rootNode.varObjOf(ident)
 			if util.TypeIsDeep(varObj.Type()) {
 				if annotation.VarIsParam(funcObj, varObj) {
 					// we've found an assignment to a parameter with deep type - have to check its deep annotation!
@@ -503,7 +517,7 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 				}
 
 				// this is an assignment to an index of a field
-				fldObj := rootNode.ObjectOf(expr.Sel).(*types.Var)
+				fldObj := rootNode.varObjOf(expr.Sel)
 				if fldObj.IsField() && util.TypeIsDeep(fldObj.Type()) {
 					return &annotation.FieldAssignDeep{
 						TriggerIfDeepNonNil: &annotation.TriggerIfDeepNonNil{
@@ -514,7 +528,7 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 			case *ast.CallExpr:
 				// check if this is a call to a function by name
 				if ident := util.FuncIdentFromCallExpr(expr); ident != nil {
-					obj := rootNode.ObjectOf(ident).(*types.Func)
+					obj := rootNode.funcObjOf(ident)
 					if obj.Type().(*types.Signature).Results().Len() != 1 {
 						return nil, errors.New("multiply returning function treated as assignment consumer")
 					}
@@ -556,9 +570,10 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 			// to an annotation site, for example, local variables.
 			// so we introspect on its type alone
 
-			if !annotation.TypeIsDeepDefaultNilable(exprType) {
+			conf := rootNode.Pass().ResultOf[config.Analyzer].(*config.Config)
+			if !annotation.TypeIsDeepDefaultNilable(exprType, conf) {
 				if ident, ok := expr.(*ast.Ident); ok {
-					varObj := rootNode.ObjectOf(ident).(*types.Var)
+					varObj := rootNode.varObjOf(ident)
 					return &annotation.LocalVarAssignDeep{
 						TriggerIfDeepNonNil: &annotation.TriggerIfDeepNonNil{
 							Ann: &annotation.LocalVarAnnotationKey{
@@ -575,7 +590,7 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 	switch expr := expr.(type) {
 	case *ast.Ident:
 		funcObj := rootNode.FuncObj()
-		varObj := rootNode.ObjectOf(expr).(*types.Var)
+		varObj := rootNode.varObjOf(expr)
 		// This block checks if the rhs of the assignment is the builtin append function for slices
 		if call, ok := exprRHS.(*ast.CallExpr); ok && util.TypeIsSlice(varObj.Type()) {
 			if fun, ok := call.Fun.(*ast.Ident); ok && fun.Name == BuiltinAppend {
@@ -615,7 +630,7 @@ func exprAsAssignmentConsumer(rootNode *RootAssertionNode, expr ast.Node, exprRH
 		return &annotation.FldAssign{
 			TriggerIfNonNil: &annotation.TriggerIfNonNil{
 				Ann: &annotation.FieldAnnotationKey{
-					FieldDecl: rootNode.ObjectOf(expr.Sel).(*types.Var),
+					FieldDecl: rootNode.varObjOf(expr.Sel),
 				},
 			},
 		}, nil
@@ -638,6 +653,216 @@ func composeRootFuncs(f1, f2 RootFunc) RootFunc {
 	}
 }
 
+// nilCaseTypeSwitchVars returns the symbolic variables of the type switches in the body (e.g., `v`
+// in "switch v := x.(type)") that are bound by a `case nil` clause, where they are always nil.
+// Note that the clauses listing other types as well (e.g., "case nil, *T") are excluded, since
+// `v` has the type of `x` and may hold its non-nil values there.
+func nilCaseTypeSwitchVars(pass *analysis.Pass, body *ast.BlockStmt) map[types.Object]bool {
+	vars := make(map[types.Object]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		clause, ok := node.(*ast.CaseClause)
+		if !ok || len(clause.List) != 1 || !pass.TypesInfo.Types[clause.List[0]].IsNil() {
+			return true
+		}
+		if obj := pass.TypesInfo.Implicits[clause]; obj != nil {
+			vars[obj] = true
+		}
+		return true
+	})
+	return vars
+}
+
+// selectSends returns the send statements in the body that are cases of select statements (e.g.,
+// `case ch <- v:`), which are never chosen if the channels are nil.
+func selectSends(body *ast.BlockStmt) map[*ast.SendStmt]bool {
+	sends := make(map[*ast.SendStmt]bool)
+	ast.Inspect(body, func(node ast.Node) bool {
+		if clause, ok := node.(*ast.CommClause); ok {
+			if send, ok := clause.Comm.(*ast.SendStmt); ok {
+				sends[send] = true
+			}
+		}
+		return true
+	})
+	return sends
+}
+
+// deferredCallsAtReturns returns the deferred calls to function literals that are executed when
+// returning via each return statement in the blocks, in the order they are deferred, along with
+// the defer statements of such calls. A call is only executed at the return statements if it is
+// deferred on every path to them (i.e., the defer statement dominates them), such that, e.g., the
+// calls deferred after an early return are not executed at it. The calls deferred conditionally
+// (e.g., in a branch or a loop), i.e., the ones reaching some return statement they do not
+// dominate, are not included, since we cannot tell whether they are executed at a return
+// statement; they are computed at the defer statements instead (see backpropAcrossDefer).
+func deferredCallsAtReturns(blocks []*cfg.Block) (map[*ast.ReturnStmt][]*ast.CallExpr, map[*ast.DeferStmt]bool) {
+	var (
+		deferredCalls map[*ast.ReturnStmt][]*ast.CallExpr
+		deferStmts    map[*ast.DeferStmt]bool
+	)
+	for _, block := range blocks {
+		if !block.Live {
+			continue
+		}
+		var rets []*ast.ReturnStmt
+		for _, node := range block.Nodes {
+			deferStmt, ok := node.(*ast.DeferStmt)
+			if !ok || calledFuncLit(deferStmt.Call) == nil {
+				continue
+			}
+			// The return statements are the same for all defer statements in the block, so they
+			// are only collected for the first one.
+			if rets == nil {
+				var dominated bool
+				if rets, dominated = dominatedReturns(blocks, block); !dominated {
+					break
+				}
+			}
+			if deferredCalls == nil {
+				deferredCalls = make(map[*ast.ReturnStmt][]*ast.CallExpr)
+			}
+			for _, ret := range rets {
+				deferredCalls[ret] = append(deferredCalls[ret], deferStmt.Call)
+			}
+			if deferStmts == nil {
+				deferStmts = make(map[*ast.DeferStmt]bool)
+			}
+			deferStmts[deferStmt] = true
+		}
+	}
+	// The blocks are not necessarily in the execution order, but the defer statements dominating
+	// the same return statement are (barring gotos) executed in the order of their positions.
+	for _, calls := range deferredCalls {
+		sort.Slice(calls, func(i, j int) bool { return calls[i].Pos() < calls[j].Pos() })
+	}
+	return deferredCalls, deferStmts
+}
+
+// dominatedReturns returns the live return statements reachable from the given block, and whether
+// all of them are dominated by the block, i.e., whether every path from the entry block to them
+// passes through the block. Note that a return statement is always the last node of a block,
+// hence it is preceded by the other nodes in the given block if they are in the same block.
+func dominatedReturns(blocks []*cfg.Block, block *cfg.Block) ([]*ast.ReturnStmt, bool) {
+	// The block dominates the blocks that become unreachable without it.
+	reachable := reachableAvoiding(blocks, block)
+	var rets []*ast.ReturnStmt
+	visited := make([]bool, len(blocks))
+	visited[block.Index] = true
+	queue := []*cfg.Block{block}
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		if ret := b.Return(); ret != nil && b.Live {
+			if reachable[b.Index] {
+				return nil, false
+			}
+			rets = append(rets, ret)
+		}
+		for _, succ := range b.Succs {
+			if !visited[succ.Index] {
+				visited[succ.Index] = true
+				queue = append(queue, succ)
+			}
+		}
+	}
+	return rets, len(rets) > 0
+}
+
+// reachableAvoiding returns whether each block is reachable from the entry block without passing
+// through the given block.
+func reachableAvoiding(blocks []*cfg.Block, avoid *cfg.Block) []bool {
+	reachable := make([]bool, len(blocks))
+	if len(blocks) == 0 || blocks[0] == avoid {
+		return reachable
+	}
+	reachable[0] = true
+	queue := []*cfg.Block{blocks[0]}
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		for _, succ := range b.Succs {
+			if succ == avoid || reachable[succ.Index] {
+				continue
+			}
+			reachable[succ.Index] = true
+			queue = append(queue, succ)
+		}
+	}
+	return reachable
+}
+
+// calledFuncLit returns the function literal called by the call, either directly (e.g., `defer
+// func() {...}()`) or via a variable it is assigned to (e.g., `go f()`), or nil if the callee is
+// not a function literal.
+func calledFuncLit(call *ast.CallExpr) *ast.FuncLit {
+	switch fun := util.StripParens(call.Fun).(type) {
+	case *ast.FuncLit:
+		return fun
+	case *ast.Ident:
+		return util.FuncLitFromAssignment(fun)
+	}
+	return nil
+}
+
+// goroutineCaptures maps the assignments that may be executed after the go statements spawning
+// function literals (see anonymousfunc.FuncLitInfo), i.e., the ones reachable from the go
+// statements in the CFG, to the function literals spawned. Since a goroutine may read the
+// variables it captures at any time after it is spawned, the values assigned to them afterward
+// may be observed by the goroutine as well (see backpropAcrossGoroutineCaptures).
+func goroutineCaptures(blocks []*cfg.Block,
+	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo) map[*ast.AssignStmt][]*anonymousfunc.FuncLitInfo {
+	var captures map[*ast.AssignStmt][]*anonymousfunc.FuncLitInfo
+	addAssignments := func(nodes []ast.Node, info *anonymousfunc.FuncLitInfo) {
+		for _, node := range nodes {
+			assign, ok := node.(*ast.AssignStmt)
+			if !ok || slices.Contains(captures[assign], info) {
+				continue
+			}
+			if captures == nil {
+				captures = make(map[*ast.AssignStmt][]*anonymousfunc.FuncLitInfo)
+			}
+			captures[assign] = append(captures[assign], info)
+		}
+	}
+
+	for _, block := range blocks {
+		if !block.Live {
+			continue
+		}
+		for i, node := range block.Nodes {
+			goStmt, ok := node.(*ast.GoStmt)
+			if !ok {
+				continue
+			}
+			funcLit := calledFuncLit(goStmt.Call)
+			if funcLit == nil {
+				continue
+			}
+			info, ok := funcLitMap[funcLit]
+			if !ok || len(info.ClosureVars) == 0 {
+				continue
+			}
+
+			// The nodes following the go statement in the same block, and all nodes in the blocks
+			// reachable from it (including the block itself if it is in a loop).
+			addAssignments(block.Nodes[i+1:], info)
+			visited := make([]bool, len(blocks))
+			queue := append([]*cfg.Block{}, block.Succs...)
+			for len(queue) > 0 {
+				b := queue[0]
+				queue = queue[1:]
+				if visited[b.Index] {
+					continue
+				}
+				visited[b.Index] = true
+				addAssignments(b.Nodes, info)
+				queue = append(queue, b.Succs...)
+			}
+		}
+	}
+	return captures
+}
+
 // This takes a cfg, and generates the information we need from it:
 //  1. its set of blocks, but with a "return" block appended that's a successor of every block that returns
 //     we need this as an index of where to start our backpropagation
@@ -103,6 +103,7 @@ func (f *FuncErrRet) equals(effect RichCheckEffect) bool {
 // Concrete examples of patterns supported are:
 // - map ok read: `v, ok := m[k]`
 // - channel ok receive: `v, ok := <-ch`
+// - type assertion ok: `v, ok := x.(T)`
 // - function ok return: `r0, r1, r2, ..., ok := f()`
 type okRead struct {
 	root  *RootAssertionNode // an associated root node
@@ -171,6 +172,19 @@ type ChannelOkRecvRefl struct {
 	okRead
 }
 
+// A TypeAssertOk is a RichCheckEffect for the `ok` in `v, ok := x.(T)` assignment. To match such an assignment,
+// both the `v` and the `ok` must be identifiers, and to have the intended effect, an `if ok { }` must
+// be encountered before an assignment to either `v` or `ok`.
+type TypeAssertOk struct {
+	okRead
+}
+
+// A TypeAssertOkRefl indicates that an interface value was type-asserted in a `v, ok := x.(T)` assignment,
+// and now if `ok` is checked it should produce non-nil for `x` because it cannot be nil if `ok` is true.
+type TypeAssertOkRefl struct {
+	okRead
+}
+
 // A FuncOkReturn is a RichCheckEffect for the `ok` in `r0, r1, r2, ..., ok := f()`, where the
 // function `f` has a final result of type `bool` - and until this is checked all other results are
 // assumed nilable. For proper invalidation, each stored return of a function is treated as a separate effect
@@ -232,10 +246,11 @@ func parseExpr(rootNode *RootAssertionNode, expr ast.Expr) TrackableExpr {
 	return parsed
 }
 
-// NodeTriggersOkRead is a case of a node creating a rich bool effect for map reads, channel receives, and user-defined
-// functions in the "ok" form. Specifically, it matches on `AssignStmt`s of the form
+// NodeTriggersOkRead is a case of a node creating a rich bool effect for map reads, channel receives, type
+// assertions, and user-defined functions in the "ok" form. Specifically, it matches on `AssignStmt`s of the form
 // - `v, ok := mp[k]`
 // - `v, ok := <-ch`
+// - `v, ok := x.(T)`
 // - `r0, r1, r2, ..., ok := f()`
 func NodeTriggersOkRead(rootNode *RootAssertionNode, nonceGenerator *util.GuardNonceGenerator, node ast.Node) ([]RichCheckEffect, bool) {
 	lhs, rhs := asthelper.ExtractLHSRHS(node)
@@ -252,7 +267,7 @@ func NodeTriggersOkRead(rootNode *RootAssertionNode, nonceGenerator *util.GuardN
 
 	var effects []RichCheckEffect
 
-	switch rhs := rhs[0].(type) {
+	switch rhs := util.StripParens(rhs[0]).(type) {
 	case *ast.IndexExpr:
 		// this is the case of `v, ok := mp[k]`. Early return if the lhs is not a map read of the expected format
 		if len(lhs) != 2 {
@@ -315,6 +330,34 @@ func NodeTriggersOkRead(rootNode *RootAssertionNode, nonceGenerator *util.GuardN
 					}})
 			}
 		}
+	case *ast.TypeAssertExpr:
+		// this is the case of `v, ok := x.(T)`. Early return if the lhs is not a type assertion of the expected format
+		if len(lhs) != 2 || rhs.Type == nil {
+			return nil, false
+		}
+
+		lhsValueParsed := parseExpr(rootNode, lhs[0])
+		if lhsValueParsed != nil {
+			// here, the lhs `value` operand is trackable
+			effects = append(effects, &TypeAssertOk{
+				okRead{
+					root:  rootNode,
+					value: lhsValueParsed,
+					ok:    lhsOkParsed,
+					guard: nonceGenerator.Next(lhs[0]),
+				}})
+		}
+
+		if rhsXParsed := parseExpr(rootNode, rhs.X); rhsXParsed != nil {
+			// here, the rhs asserted operand is trackable
+			effects = append(effects, &TypeAssertOkRefl{
+				okRead{
+					root:  rootNode,
+					value: rhsXParsed,
+					ok:    lhsOkParsed,
+					guard: nonceGenerator.Next(rhs.X),
+				}})
+		}
 	case *ast.CallExpr:
 		callIdent := util.FuncIdentFromCallExpr(rhs)
 		if callIdent == nil {
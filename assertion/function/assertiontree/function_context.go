@@ -63,6 +63,31 @@ type FunctionContext struct {
 
 	// funcContracts stores the function contracts of all the functions.
 	funcContracts functioncontracts.Map
+
+	// noReturnCalls stores the calls to the functions that never return but are not known to the
+	// ctrlflow analysis (see noreturn.Result).
+	noReturnCalls map[*ast.CallExpr]bool
+
+	// deferredCalls maps the return statements to the deferred calls to function literals that
+	// are executed when returning via them, and deferredAtReturns stores the defer statements of
+	// such calls (see deferredCallsAtReturns).
+	deferredCalls     map[*ast.ReturnStmt][]*ast.CallExpr
+	deferredAtReturns map[*ast.DeferStmt]bool
+
+	// shortCircuitExprs stores the short-circuiting boolean expressions that are split from the
+	// nodes into the preceding blocks of the CFG (see splitBlockOnShortCircuits).
+	shortCircuitExprs map[ast.Expr]bool
+
+	// nilCaseTypeSwitchVars stores the symbolic variables of type switches that are bound by a
+	// `case nil` clause (see nilCaseTypeSwitchVars).
+	nilCaseTypeSwitchVars map[types.Object]bool
+
+	// selectSends stores the send statements that are cases of select statements.
+	selectSends map[*ast.SendStmt]bool
+
+	// goroutineCaptures maps the assignments that may be executed after spawning goroutines of
+	// function literals to the function literals (see goroutineCaptures).
+	goroutineCaptures map[*ast.AssignStmt][]*anonymousfunc.FuncLitInfo
 }
 
 // FunctionConfig is meant to hold all the user set configuration for analyzing a function
@@ -71,6 +96,9 @@ type FunctionConfig struct {
 	EnableStructInitCheck bool
 	// EnableAnonymousFunc is a flag to enable checking anonymous functions.
 	EnableAnonymousFunc bool
+	// MaxBackpropRounds is the maximum number of backpropagation rounds across the function
+	// before it is abandoned with ErrBudgetExceeded, or non-positive for no limit.
+	MaxBackpropRounds int
 }
 
 // NewFunctionContext returns a new FunctionContext and initializes all the maps
@@ -82,6 +110,7 @@ func NewFunctionContext(
 	funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo,
 	pkgFakeIdentMap map[*ast.Ident]types.Object,
 	funcContracts functioncontracts.Map,
+	noReturnCalls map[*ast.CallExpr]bool,
 ) FunctionContext {
 	return FunctionContext{
 		pass:                    pass,
@@ -93,6 +122,7 @@ func NewFunctionContext(
 		funcLitMap:              funcLitMap,
 		pkgFakeIdentMap:         pkgFakeIdentMap,
 		funcContracts:           funcContracts,
+		noReturnCalls:           noReturnCalls,
 	}
 }
 
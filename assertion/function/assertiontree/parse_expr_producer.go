@@ -20,6 +20,7 @@ import (
 	"go/types"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/function/functioncontracts"
 	"go.uber.org/nilaway/assertion/function/producer"
 	"go.uber.org/nilaway/util"
 )
@@ -75,7 +76,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 		}
 
 		funcObj := r.FuncObj()
-		varObj := r.ObjectOf(expr).(*types.Var)
+		varObj := r.varObjOf(expr)
 		if doNotTrack {
 			if annotation.VarIsRecv(funcObj, varObj) {
 				return nil, []producer.ParsedProducer{producer.DeepParsedProducer{
@@ -129,7 +130,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 		}
 
 		// by process of elimination, it's a variable, so track it!
-		return TrackableExpr{&varAssertionNode{decl: r.ObjectOf(expr).(*types.Var)}}, nil
+		return TrackableExpr{&varAssertionNode{decl: r.varObjOf(expr)}}, nil
 	}
 
 	// this function represents the case in which we have identified that the value of the
@@ -199,7 +200,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 		}
 
 		fldReadProduce := func() []producer.ParsedProducer {
-			fldObj := r.ObjectOf(expr.Sel).(*types.Var)
+			fldObj := r.varObjOf(expr.Sel)
 			return []producer.ParsedProducer{producer.DeepParsedProducer{
 				ShallowProducer: &annotation.ProduceTrigger{
 					Annotation: &annotation.FldRead{
@@ -222,7 +223,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 
 		if recv, _ := r.ParseExprAsProducer(expr.X, false); recv != nil {
 			// trackable access to a field
-			return append(recv, &fldAssertionNode{decl: r.ObjectOf(expr.Sel).(*types.Var),
+			return append(recv, &fldAssertionNode{decl: r.varObjOf(expr.Sel),
 				functionContext: r.functionContext}), nil
 		}
 		// non-trackable access to a field - just return a produce trigger for that field
@@ -236,7 +237,9 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 					return false
 				}
 			}
-			return true
+			// The results of a call matching a contract of the function are determined by the
+			// contract instead (see getFuncReturnProducers), so the call is not tracked.
+			return r.matchContract(expr) == nil
 		}
 
 		if ret, ok := AsTrustedFuncAction(expr, r.Pass()); ok {
@@ -245,6 +248,16 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			}
 		}
 
+		// The results of the calls to the function literals are not tracked, except for the
+		// function literals with contracts, which are honoured like the declared functions.
+		if ident := getFuncIdent(expr, &r.functionContext); ident != nil && ident != util.FuncIdentFromCallExpr(expr) {
+			if funcObj, ok := r.ObjectOf(ident).(*types.Func); ok {
+				if _, ok := r.functionContext.funcContracts[funcObj]; ok {
+					return nil, r.getFuncReturnProducers(ident, expr)
+				}
+			}
+		}
+
 		// the cases of a function and method call are different enough here that it would be useless
 		// to try to subsume this switch with funcIdentFromCallExpr
 		switch fun := expr.Fun.(type) {
@@ -283,7 +296,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			// non-builtin funcs
 			if !doNotTrack && litArgs() {
 				return TrackableExpr{&funcAssertionNode{
-					decl: r.ObjectOf(fun).(*types.Func), args: expr.Args}}, nil
+					decl: r.funcObjOf(fun), args: expr.Args}}, nil
 			}
 			// function call has non-literal args, so is not literal, use its return annotation
 			// alternatively, doNotTrack was set
@@ -300,11 +313,11 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 			if litArgs() {
 				if r.isPkgName(fun.X) {
 					return TrackableExpr{&funcAssertionNode{
-						decl: r.ObjectOf(fun.Sel).(*types.Func), args: expr.Args}}, nil
+						decl: r.funcObjOf(fun.Sel), args: expr.Args}}, nil
 				}
 				if recv, _ := r.ParseExprAsProducer(fun.X, false); recv != nil {
 					return append(recv, &funcAssertionNode{
-						decl: r.ObjectOf(fun.Sel).(*types.Func), args: expr.Args}), nil
+						decl: r.funcObjOf(fun.Sel), args: expr.Args}), nil
 				}
 				// receiver is not trackable, use its return annotation
 				return nil, r.getFuncReturnProducers(fun.Sel, expr)
@@ -407,7 +420,7 @@ func (r *RootAssertionNode) ParseExprAsProducer(expr ast.Expr, doNotTrack bool)
 
 // getFuncReturnProducers returns a list of producers that are triggered at the call expression
 func (r *RootAssertionNode) getFuncReturnProducers(ident *ast.Ident, expr *ast.CallExpr) []producer.ParsedProducer {
-	funcObj := r.ObjectOf(ident).(*types.Func)
+	funcObj := r.funcObjOf(ident)
 
 	numResults := util.FuncNumResults(funcObj)
 	isErrReturning := util.FuncIsErrReturning(funcObj)
@@ -415,6 +428,15 @@ func (r *RootAssertionNode) getFuncReturnProducers(ident *ast.Ident, expr *ast.C
 
 	producers := make([]producer.ParsedProducer, numResults)
 
+	// For a contract matching the call, the results with `nonnil` or `nil` output values are
+	// determined by the contract. For an error-returning (or ok-returning) function, `nil` (or
+	// `true`) for the last result is the condition for the other results instead, which is
+	// enforced by guarding them as usual.
+	contract := r.matchContract(expr)
+	isConditional := contract != nil && numResults > 1 &&
+		((isErrReturning && contract.Outs[numResults-1] == functioncontracts.Nil) ||
+			(isOkReturning && contract.Outs[numResults-1] == functioncontracts.True))
+
 	for i := 0; i < numResults; i++ {
 		var retKey annotation.Key
 		if r.HasContract(funcObj) {
@@ -432,19 +454,42 @@ func (r *RootAssertionNode) getFuncReturnProducers(ident *ast.Ident, expr *ast.C
 			fieldProducers = r.getFieldProducersForFuncReturns(funcObj, i)
 		}
 
+		// for an error-returning function, all but the last result are guarded
+		// TODO: add an annotation that allows more results to escape from guarding
+		// such as "error-nonnil" or "always-nonnil"
+		needsGuard := (isErrReturning || isOkReturning) && i != numResults-1
+
+		var shallowAnnotation annotation.ProducingAnnotationTrigger = &annotation.FuncReturn{
+			TriggerIfNilable: &annotation.TriggerIfNilable{
+				Ann:        retKey,
+				NeedsGuard: needsGuard,
+			},
+		}
+		if contract != nil && !(isConditional && i == numResults-1) {
+			// An unconditional contract holds regardless of the guards.
+			needsGuard = needsGuard && isConditional
+			switch contract.Outs[i] {
+			case functioncontracts.NonNil:
+				shallowAnnotation = &annotation.FuncReturnNonnilByContract{
+					ProduceTriggerNever: &annotation.ProduceTriggerNever{NeedsGuard: needsGuard},
+					FuncName:            funcObj.Name(),
+					RetNum:              i,
+					Contract:            contract.String(),
+				}
+			case functioncontracts.Nil:
+				shallowAnnotation = &annotation.FuncReturnNilByContract{
+					ProduceTriggerTautology: &annotation.ProduceTriggerTautology{NeedsGuard: needsGuard},
+					FuncName:                funcObj.Name(),
+					RetNum:                  i,
+					Contract:                contract.String(),
+				}
+			}
+		}
+
 		producers[i] = producer.DeepParsedProducer{
 			ShallowProducer: &annotation.ProduceTrigger{
-				Annotation: &annotation.FuncReturn{
-					TriggerIfNilable: &annotation.TriggerIfNilable{
-						Ann: retKey,
-
-						// for an error-returning function, all but the last result are guarded
-						// TODO: add an annotation that allows more results to escape from guarding
-						// such as "error-nonnil" or "always-nonnil"
-						NeedsGuard: (isErrReturning || isOkReturning) && i != numResults-1,
-					},
-				},
-				Expr: expr,
+				Annotation: shallowAnnotation,
+				Expr:       expr,
 			},
 			DeepProducer: &annotation.ProduceTrigger{
 				Annotation: annotation.DeepNilabilityOfFuncRet(funcObj, i),
@@ -21,6 +21,7 @@ import (
 
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
 	"golang.org/x/tools/go/cfg"
 )
 
@@ -35,7 +36,7 @@ func preprocess(graph *cfg.CFG, fc FunctionContext) (*cfg.CFG, [][]RichCheckEffe
 	// modify them directly. Here, we make a copy of the graph (and all blocks in it) and modify
 	// the copied graph instead.
 	graph = copyGraph(graph)
-	restructureBlocks(graph, fc.pass)
+	restructureBlocks(graph, fc.pass, fc.noReturnCalls, fc.shortCircuitExprs)
 	richCheckBlocks, exprNonceMap := genInitialRichCheckEffects(graph, fc)
 	richCheckBlocks = propagateRichChecks(graph, richCheckBlocks)
 
@@ -160,7 +161,17 @@ func genInitialRichCheckEffects(graph *cfg.CFG, functionContext FunctionContext)
 // In addition, it also performs the following transformations to standardize explicit boolean comparisons:
 // - replace if x == true {T} {F} with if x {T} {F}
 // - replace if x == false {T} {F} with if !x {T} {F}
-func restructureBlocks(graph *cfg.CFG, pass *analysis.Pass) {
+//
+// The short-circuiting boolean expressions in return statements and assignments that check the
+// `ok` of comma-ok type assertions (e.g., `return ok && v.f`) are restructured in the same way as
+// the conditions, by splitting them from the nodes into the preceding blocks (see
+// splitBlockOnShortCircuits).
+//
+// Finally, the blocks are cut at the calls to the functions that never return but are not known to
+// the ctrlflow analysis building the CFG (e.g., `tb.Fatal`), such that the code following them is
+// treated as dead.
+func restructureBlocks(graph *cfg.CFG, pass *analysis.Pass, noReturnCalls map[*ast.CallExpr]bool,
+	shortCircuitExprs map[ast.Expr]bool) {
 	failureBlock := &cfg.Block{
 		Nodes: nil,
 		Succs: nil,
@@ -173,9 +184,15 @@ func restructureBlocks(graph *cfg.CFG, pass *analysis.Pass) {
 	// referenced by index!
 	for _, block := range graph.Blocks {
 		if block.Live {
+			cutBlockAtNoReturnCall(block, noReturnCalls)
 			splitBlockOnTrustedFuncs(graph, block, failureBlock, pass)
 		}
 	}
+	for _, block := range graph.Blocks {
+		if block.Live {
+			splitBlockOnShortCircuits(graph, block, shortCircuitExprs)
+		}
+	}
 	for _, block := range graph.Blocks {
 		if block.Live {
 			restructureBlock(graph, block)
@@ -183,6 +200,118 @@ func restructureBlocks(graph *cfg.CFG, pass *analysis.Pass) {
 	}
 }
 
+// cutBlockAtNoReturnCall drops the nodes following the first call to a function that never
+// returns in the block, as well as the successors of the block. Since the block no longer reaches
+// the return block, no assertions are propagated to it, i.e., the block is effectively dead.
+func cutBlockAtNoReturnCall(thisBlock *cfg.Block, noReturnCalls map[*ast.CallExpr]bool) {
+	for i, node := range thisBlock.Nodes {
+		expr, ok := node.(*ast.ExprStmt)
+		if !ok {
+			continue
+		}
+		if call, ok := expr.X.(*ast.CallExpr); ok && noReturnCalls[call] {
+			thisBlock.Nodes = thisBlock.Nodes[:i+1]
+			thisBlock.Succs = nil
+			return
+		}
+	}
+}
+
+// splitBlockOnShortCircuits splits the block before each return statement or assignment that has
+// a short-circuiting boolean expression (i.e., `&&` or `||`) checking the `ok` of a comma-ok type
+// assertion as a result or right-hand side. The expression is placed at the end of the first block
+// as a condition whose branches both lead to the second block starting with the node, such that
+// restructureBlock reflects its short-circuiting in the same way as for the conditions of if
+// statements, e.g., the dereference in `return ok && v.f` is guarded by the check of `ok`. The
+// split expressions are stored in shortCircuitExprs, since they are already computed in the first
+// block when the node is reached (see AddComputation).
+//
+// The other short-circuiting expressions are kept in the nodes, since splitting them would change
+// the (deliberately non-conditional) flows of the values computed in them.
+func splitBlockOnShortCircuits(graph *cfg.CFG, thisBlock *cfg.Block, shortCircuitExprs map[ast.Expr]bool) {
+	for i, node := range thisBlock.Nodes {
+		var exprs []ast.Expr
+		switch node := node.(type) {
+		case *ast.ReturnStmt:
+			exprs = node.Results
+		case *ast.AssignStmt:
+			exprs = node.Rhs
+		case *ast.ValueSpec:
+			exprs = node.Values
+		}
+		for _, expr := range exprs {
+			binExpr, ok := util.StripParens(expr).(*ast.BinaryExpr)
+			if !ok || shortCircuitExprs[binExpr] || !checksCommaOkAssertion(binExpr) {
+				continue
+			}
+
+			newBlock := &cfg.Block{
+				Nodes: append([]ast.Node{}, thisBlock.Nodes[i:]...),
+				Succs: thisBlock.Succs,
+				Index: int32(len(graph.Blocks)),
+				Live:  true,
+			}
+			graph.Blocks = append(graph.Blocks, newBlock)
+			thisBlock.Nodes = append(thisBlock.Nodes[:i], binExpr)
+			thisBlock.Succs = []*cfg.Block{newBlock, newBlock}
+			shortCircuitExprs[binExpr] = true
+			// The node may have other short-circuiting expressions, and the following nodes as well.
+			splitBlockOnShortCircuits(graph, newBlock, shortCircuitExprs)
+			return
+		}
+	}
+}
+
+// checksCommaOkAssertion returns true if the expression is a short-circuiting boolean expression
+// with an operand (possibly negated) that is the `ok` of a comma-ok type assertion, e.g., `ok` in
+// `v, ok := x.(*T)`.
+func checksCommaOkAssertion(expr ast.Expr) bool {
+	binExpr, ok := astutil.Unparen(expr).(*ast.BinaryExpr)
+	if !ok || (binExpr.Op != token.LAND && binExpr.Op != token.LOR) {
+		return false
+	}
+	for _, operand := range [...]ast.Expr{binExpr.X, binExpr.Y} {
+		operand = astutil.Unparen(operand)
+		if unary, ok := operand.(*ast.UnaryExpr); ok && unary.Op == token.NOT {
+			operand = astutil.Unparen(unary.X)
+		}
+		if ident, ok := operand.(*ast.Ident); ok && isCommaOkAssertionOk(ident) {
+			return true
+		}
+		if checksCommaOkAssertion(operand) {
+			return true
+		}
+	}
+	return false
+}
+
+// isCommaOkAssertionOk returns true if the identifier is declared as the `ok` of a comma-ok type
+// assertion, e.g., `ok` in `v, ok := x.(*T)` or `var v, ok = x.(*T)`.
+func isCommaOkAssertionOk(ident *ast.Ident) bool {
+	if ident.Obj == nil || ident.Obj.Decl == nil {
+		return false
+	}
+	var lhs []*ast.Ident
+	var rhs []ast.Expr
+	switch decl := ident.Obj.Decl.(type) {
+	case *ast.AssignStmt:
+		for _, expr := range decl.Lhs {
+			lhsIdent, _ := expr.(*ast.Ident)
+			lhs = append(lhs, lhsIdent)
+		}
+		rhs = decl.Rhs
+	case *ast.ValueSpec:
+		lhs, rhs = decl.Names, decl.Values
+	default:
+		return false
+	}
+	if len(lhs) != 2 || len(rhs) != 1 || lhs[1] == nil || lhs[1].Obj != ident.Obj {
+		return false
+	}
+	_, ok := astutil.Unparen(rhs[0]).(*ast.TypeAssertExpr)
+	return ok
+}
+
 func splitBlockOnTrustedFuncs(graph *cfg.CFG, thisBlock, failureBlock *cfg.Block, pass *analysis.Pass) {
 	var expr *ast.ExprStmt
 	var call *ast.CallExpr
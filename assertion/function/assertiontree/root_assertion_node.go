@@ -22,9 +22,12 @@ import (
 	"go/types"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/assertion/anonymousfunc"
+	"go.uber.org/nilaway/assertion/function/functioncontracts"
 	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
 )
 
 // RootAssertionNode is the object that will be directly handled by the propagation algorithm,
@@ -54,10 +57,68 @@ func (r *RootAssertionNode) LocationOf(expr ast.Expr) token.Position {
 	return util.PosToLocation(expr.Pos(), r.Pass())
 }
 
-// HasContract returns if the given function has any contracts.
+// HasContract returns if the given function has a contract applied by duplicating its triggers at
+// every call site, i.e., the single contract `contract(nonnil -> nonnil)`. The other contracts are
+// evaluated at the call sites instead (see matchContract).
 func (r *RootAssertionNode) HasContract(funcObj *types.Func) bool {
-	_, ok := r.functionContext.funcContracts[funcObj]
-	return ok
+	return r.functionContext.funcContracts.HasOnlyNonNilToNonNil(funcObj)
+}
+
+// matchContract returns the first contract of the called function whose input values are
+// satisfied by the arguments of the call, or nil if there is none. Only the input values that can
+// be decided syntactically are evaluated here, i.e., `_`, `nil` for the nil literal, and `true`
+// and `false` for the constant bools, hence a contract with `nonnil` inputs never matches (see
+// HasContract for applying `contract(nonnil -> nonnil)`).
+func (r *RootAssertionNode) matchContract(call *ast.CallExpr) *functioncontracts.FunctionContract {
+	funcObj := CalleeOf(r.Pass(), call, r.functionContext.funcLitMap)
+	if funcObj == nil {
+		return nil
+	}
+	contracts, ok := r.functionContext.funcContracts[funcObj]
+	if !ok {
+		return nil
+	}
+	// The arguments of variadic calls (and calls like `f(g())`) do not correspond to the params
+	// one to one, so we do not match them for simplicity. Note that the arguments of a call to a
+	// function literal are extended with the closure variables, which are not covered by the
+	// contracts.
+	args := r.funcArgsFromCallExpr(call)
+	sig := funcObj.Type().(*types.Signature)
+	if sig.Variadic() || len(args) != sig.Params().Len() {
+		return nil
+	}
+
+	for _, contract := range contracts {
+		matched := true
+		for i, in := range contract.Ins {
+			if !r.argMatchesContractVal(args[i], in) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return contract
+		}
+	}
+	return nil
+}
+
+// argMatchesContractVal returns true if the argument is known to satisfy the contract value.
+func (r *RootAssertionNode) argMatchesContractVal(arg ast.Expr, val functioncontracts.ContractVal) bool {
+	switch val {
+	case functioncontracts.Any:
+		return true
+	case functioncontracts.Nil:
+		ident, ok := astutil.Unparen(arg).(*ast.Ident)
+		return ok && r.isNil(ident)
+	case functioncontracts.True, functioncontracts.False:
+		tv, ok := r.Pass().TypesInfo.Types[arg]
+		if !ok || tv.Value == nil || tv.Value.Kind() != constant.Bool {
+			return false
+		}
+		return constant.BoolVal(tv.Value) == (val == functioncontracts.True)
+	}
+	return false
 }
 
 // MinimalString for a RootAssertionNode returns a minimal string representation of that root node
@@ -112,7 +173,7 @@ func (r *RootAssertionNode) Size() int {
 // FuncObj returns the underlying function declaration of this node as a types.Func
 func (r *RootAssertionNode) FuncObj() *types.Func {
 	if r.funcObj == nil {
-		r.funcObj = r.ObjectOf(r.FuncNameIdent()).(*types.Func)
+		r.funcObj = r.funcObjOf(r.FuncNameIdent())
 	}
 	return r.funcObj
 }
@@ -129,11 +190,14 @@ func (r *RootAssertionNode) GetTriggers() []annotation.FullTrigger {
 	return r.triggers
 }
 
-// GetDeclaringIdent finds the identifier that serves as the declaration of the passed object
+// GetDeclaringIdent finds the identifier that serves as the declaration of the passed object. Note
+// that the identifier found at the position of the object may not denote it, e.g., the symbolic
+// variable `v` of a type switch `switch v := x.(type)` denotes none of the objects implicitly
+// declared in the case clauses, in which case a fake identifier is created instead.
 func (r *RootAssertionNode) GetDeclaringIdent(obj types.Object) *ast.Ident {
 
 	if path, ok := GetDeclaringPath(r.Pass(), obj.Pos(), obj.Pos()); ok && len(path) > 0 {
-		if ident, ok := path[0].(*ast.Ident); ok && ident.Name == obj.Name() {
+		if ident, ok := path[0].(*ast.Ident); ok && ident.Name == obj.Name() && r.Pass().TypesInfo.ObjectOf(ident) == obj {
 			return ident
 		}
 		// In case the declaration is package.ident
@@ -166,6 +230,26 @@ func (r *RootAssertionNode) ObjectOf(ident *ast.Ident) types.Object {
 	return r.functionContext.findFakeIdent(ident)
 }
 
+// funcObjOf returns the function denoted by the identifier. It must only be called on the
+// identifiers known to denote functions, and panics otherwise.
+func (r *RootAssertionNode) funcObjOf(ident *ast.Ident) *types.Func {
+	funcObj, ok := r.ObjectOf(ident).(*types.Func)
+	if !ok {
+		panic(fmt.Sprintf("identifier %q does not denote a function", ident.Name))
+	}
+	return funcObj
+}
+
+// varObjOf returns the variable (or field) denoted by the identifier. It must only be called on
+// the identifiers known to denote variables, and panics otherwise.
+func (r *RootAssertionNode) varObjOf(ident *ast.Ident) *types.Var {
+	varObj, ok := r.ObjectOf(ident).(*types.Var)
+	if !ok {
+		panic(fmt.Sprintf("identifier %q does not denote a variable", ident.Name))
+	}
+	return varObj
+}
+
 // funcArgsFromCallExpr returns the set of arguments that are passed to the method at the call site. If the method
 // is an anonymous function, it expands the argument set with the closure variables collected for that function
 func (r *RootAssertionNode) funcArgsFromCallExpr(expr *ast.CallExpr) []ast.Expr {
@@ -174,7 +258,7 @@ func (r *RootAssertionNode) funcArgsFromCallExpr(expr *ast.CallExpr) []ast.Expr
 	if ident, ok := fun.(*ast.Ident); ok {
 		// if the declaration of the ident points to a function literal node,
 		// then update fun with the function literal node
-		if funcLit := getFuncLitFromAssignment(ident); funcLit != nil {
+		if funcLit := util.FuncLitFromAssignment(ident); funcLit != nil {
 			fun = funcLit
 		}
 	}
@@ -550,6 +634,10 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 	// assignments and branching can't happen within expressions in Go, the order in
 	// which we recur doesn't matter
 	case *ast.BinaryExpr:
+		if r.functionContext.shortCircuitExprs[expr] {
+			// already computed in the blocks preceding the node (see splitBlockOnShortCircuits)
+			return
+		}
 		// process the binary expression `X op Y` in reverse, i.e., add consumers for Y first and then X
 		r.AddComputation(expr.Y)
 
@@ -587,7 +675,7 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 				if argFunc, ok := exprArgs[0].(*ast.CallExpr); ok {
 					handleArgFuncIdent := func(argFuncIdent *ast.Ident) bool {
 						if r.isFunc(argFuncIdent) {
-							funcObj := r.ObjectOf(argFuncIdent).(*types.Func)
+							funcObj := r.funcObjOf(argFuncIdent)
 							if n := util.FuncNumResults(funcObj); n > 1 {
 								// is a pass of a multiply returning function to another function
 								_, producers := r.ParseExprAsProducer(argFunc, true)
@@ -706,10 +794,11 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 			}
 		}
 
-		if fun := getFuncIdent(expr, &r.functionContext); fun != nil && r.isFunc(fun) {
+		fun := getFuncIdent(expr, &r.functionContext)
+		if fun != nil && r.isFunc(fun) {
 			// here we have found a call to a function whose declaration we have access to,
 			// so we can mark its arguments as consumed
-			consumeArg = consumeArgTrigger(r.ObjectOf(fun).(*types.Func))
+			consumeArg = consumeArgTrigger(r.funcObjOf(fun))
 
 			if r.functionContext.functionConfig.EnableStructInitCheck {
 				// Add Productions for struct field params
@@ -723,6 +812,15 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 			// or a typecast like int(x) - in either case (at least for now), do nothing to try
 			// to consume the arguments
 			consumeArg = consumeArgNoop
+
+			// closing a nil channel panics, so the channel must be non-nil
+			if fun != nil && r.Pass().TypesInfo.ObjectOf(fun) == util.BuiltinClose && len(expr.Args) == 1 {
+				r.AddConsumption(&annotation.ConsumeTrigger{
+					Annotation: &annotation.ChanClose{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}},
+					Expr:       expr.Args[0],
+					Guards:     util.NoGuards(),
+				})
+			}
 		}
 
 		// when we reach this point, consumeArg will be set to a no-op exactly if we don't know
@@ -841,20 +939,35 @@ func (r *RootAssertionNode) AddComputation(expr ast.Expr) {
 		})
 		r.AddComputation(expr.X)
 	case *ast.TypeAssertExpr:
-		// doesn't need to be non-nil, but really should be
+		// the single-value form of type assertions (`x.(T)`) panics if x is nil, so it must be
+		// non-nil. Note that the comma-ok form is computed separately (see backpropAcrossAssignment),
+		// and that `x.(type)` in type switches (where expr.Type is nil) never panics.
+		if expr.Type != nil {
+			r.AddConsumption(&annotation.ConsumeTrigger{
+				Annotation: &annotation.TypeAssert{ConsumeTriggerTautology: &annotation.ConsumeTriggerTautology{}},
+				Expr:       expr.X,
+				Guards:     util.NoGuards(),
+				// Values read from maps of interfaces are idiomatically type-asserted in this form
+				// when the keys are known to be present (e.g., `pass.ResultOf[a].(*T)`), so similar
+				// to range statements, we do not require such reads to be guarded here.
+				GuardMatched: true,
+			})
+		}
 		r.AddComputation(expr.X)
 	case *ast.UnaryExpr:
 		// Note if expr.Op == token.ARROW it represents a channel receive (<-X), and we have:
 		// (1) A receive from a nil channel blocks forever;
 		// (2) A receive from a closed channel returns the zero value immediately.
-		// (1) falls out of scope of NilAway, and we have a lot of valid Go code that receives
-		// from nil channels (e.g., select statements with nilable channels). So we do not create
-		// consumer for the channel variable here. For (2), since we currently do not track the
-		// state of channels, we currently cannot support it either.
-		// TODO: rethink our strategy of handling channels (#192).
+		// For (1), unlike sends (see backpropAcrossSend), we have a lot of valid Go code that
+		// receives from nil channels (e.g., select statements with nilable channels, or loops
+		// waiting for a channel to be set), so we do not create consumer for the channel variable
+		// here. (2) is modeled by the guards of the `v, ok := <-ch` form (see ChannelOkRecv).
 		r.AddComputation(expr.X)
 	case *ast.FuncLit:
-		// TODO: analyze the bodies of anonymous functions
+		// The bodies of the function literals are analyzed separately as fake function
+		// declarations (see anonymousfunc.FuncLitInfo): all of them if the experimental anonymous
+		// function support is enabled, or only the ones spawned as goroutines or deferred
+		// otherwise.
 	default:
 		// TODO - once debugger is working - fill in cases here
 		// if we don't recognize the node - do nothing
@@ -873,7 +986,7 @@ func getFuncIdent(expr *ast.CallExpr, fc *FunctionContext) *ast.Ident {
 		funcLit, _ = expr.Fun.(*ast.FuncLit)
 	} else {
 		// check if the declaration the ident points to a function literal node
-		funcLit = getFuncLitFromAssignment(ident)
+		funcLit = util.FuncLitFromAssignment(ident)
 	}
 
 	if funcLit != nil {
@@ -885,32 +998,30 @@ func getFuncIdent(expr *ast.CallExpr, fc *FunctionContext) *ast.Ident {
 	return ident
 }
 
-// getFuncLitFromAssignment if the declaration of the ident is an assignment
-// statement and Rhs of the assignment is a call expression which represents an
-// anonymous function, returns the ident of the fake function declaration created
-// for that. Otherwise, return nil.
-func getFuncLitFromAssignment(ident *ast.Ident) *ast.FuncLit {
-	if ident.Obj == nil || ident.Obj.Decl == nil {
-		return nil
-	}
-
-	if assign, ok := ident.Obj.Decl.(*ast.AssignStmt); ok {
-		// TODO get the correct ident for many to one assignments
-		if len(assign.Lhs) != len(assign.Rhs) {
-			return nil
-		}
+// CalleeOf returns the function called by the call expression, which is the fake function object
+// (see anonymousfunc.FuncLitInfo) for a call to a function literal, or nil if the callee is not a
+// declared function or an analyzed function literal (e.g., a builtin or a function-typed param).
+func CalleeOf(pass *analysis.Pass, call *ast.CallExpr, funcLitMap map[*ast.FuncLit]*anonymousfunc.FuncLitInfo) *types.Func {
+	ident := util.FuncIdentFromCallExpr(call)
 
-		for i := range assign.Lhs {
-			if assign.Lhs[i].(*ast.Ident).Obj != ident.Obj {
-				continue
-			}
-			if rhs, ok := assign.Rhs[i].(*ast.FuncLit); ok {
-				return rhs
-			}
+	var funcLit *ast.FuncLit
+	if ident == nil {
+		funcLit, _ = call.Fun.(*ast.FuncLit)
+	} else {
+		funcLit = util.FuncLitFromAssignment(ident)
+	}
+	if funcLit != nil {
+		if info, ok := funcLitMap[funcLit]; ok {
+			return info.FakeFuncObj
 		}
+		return nil
 	}
 
-	return nil
+	if ident == nil {
+		return nil
+	}
+	funcObj, _ := pass.TypesInfo.ObjectOf(ident).(*types.Func)
+	return funcObj
 }
 
 // LiftFromPath takes a `path` of assertion nodes, and searches for it in the assertion tree rooted
@@ -1004,11 +1115,37 @@ func (r *RootAssertionNode) ProcessEntry() {
 		)
 	}
 
+	// A nil interface value never flows to an expression of a non-interface type, since the
+	// only ways for an interface value to do so (i.e., type assertions and type switches) fail
+	// on nil interfaces. For example, `v` is never nil in `if v, ok := x.(*T); ok {...}` if `x`
+	// is a nil interface, but it is if `x` holds a nil `*T`.
+	filtered := r.triggers[:0]
+	for _, t := range r.triggers {
+		if !r.isNilInterfaceFlow(t) {
+			filtered = append(filtered, t)
+		}
+	}
+	r.triggers = filtered
+
 	for i := range r.triggers {
 		r.triggers[i] = CheckGuardOnFullTrigger(r.triggers[i])
 	}
 }
 
+// isNilInterfaceFlow returns true if the full trigger is a flow from an expression of an
+// interface type to an expression of a non-interface type (see ProcessEntry).
+func (r *RootAssertionNode) isNilInterfaceFlow(t annotation.FullTrigger) bool {
+	if t.Producer.Expr == nil || t.Consumer.Expr == nil {
+		return false
+	}
+	producerType := r.Pass().TypesInfo.TypeOf(t.Producer.Expr)
+	consumerType := r.Pass().TypesInfo.TypeOf(t.Consumer.Expr)
+	if producerType == nil || consumerType == nil {
+		return false
+	}
+	return types.IsInterface(producerType) && !types.IsInterface(consumerType)
+}
+
 // performs a shallow comparison of two nodes - doesn't recur into their subtrees and doesn't look at triggers
 // invariant on AssertionNodes is that this can never hold between any two of their distinct children
 func (r *RootAssertionNode) shallowEqNodes(left, right AssertionNode) bool {
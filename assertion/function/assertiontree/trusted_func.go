@@ -22,6 +22,7 @@ import (
 	"regexp"
 
 	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/config"
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
 )
@@ -30,9 +31,24 @@ import (
 
 // AsTrustedFuncAction checks a function call AST node to see if it is one of the trusted functions, and if it is then runs
 // the corresponding action and returns that as the output along with a bool indicating success or failure.
-// For example, a binary expression `x != nil` is returned for trusted function `assert.NotNil(t, x)`, while a `TrustedFuncNonnil` producer is returned for `errors.New(s)`
+// For example, a binary expression `x != nil` is returned for trusted function `assert.NotNil(t, x)`, while a `TrustedFuncNonnil` producer is returned for `errors.New(s)`.
+// The trusted functions declared by the users in the config take precedence over the built-in ones.
 func AsTrustedFuncAction(expr ast.Expr, p *analysis.Pass) (any, bool) {
 	if call, ok := expr.(*ast.CallExpr); ok {
+		if conf, ok := p.ResultOf[config.Analyzer].(*config.Config); ok {
+			for _, userFunc := range conf.TrustedFuncs() {
+				// The no-return functions are handled by the noreturn analyzer instead.
+				if userFunc.Effect == config.TrustedFuncNoReturn {
+					continue
+				}
+				f, a := asTrustedFunc(userFunc)
+				if f.match(call, p) {
+					if t := a.action(call, a.argIndex, p); t != nil {
+						return t, true
+					}
+				}
+			}
+		}
 		for f, a := range trustedFuncs {
 			if f.match(call, p) {
 				if t := a.action(call, a.argIndex, p); t != nil {
@@ -152,6 +168,13 @@ var nonnilProducer action = func(call *ast.CallExpr, _ int, _ *analysis.Pass) an
 	}
 }
 
+var nilableProducer action = func(call *ast.CallExpr, _ int, _ *analysis.Pass) any {
+	return &annotation.ProduceTrigger{
+		Annotation: &annotation.TrustedFuncNilable{ProduceTriggerTautology: &annotation.ProduceTriggerTautology{}},
+		Expr:       call,
+	}
+}
+
 func newNilBinaryExpr(arg ast.Expr, op token.Token) *ast.BinaryExpr {
 	return &ast.BinaryExpr{
 		X:     arg,
@@ -471,6 +494,29 @@ var trustedFuncs = map[trustedFuncSig]trustedFuncAction{
 	}: {action: requireZeroComparators, argIndex: 0},
 }
 
+// _userTrustedFuncActions maps the effects of the user-declared trusted functions to their actions.
+var _userTrustedFuncActions = map[config.TrustedFuncEffect]action{
+	config.TrustedFuncArgNonnil:     nonnilBinaryExpr,
+	config.TrustedFuncArgNil:        nilBinaryExpr,
+	config.TrustedFuncArgTrue:       selfExpr,
+	config.TrustedFuncResultNonnil:  nonnilProducer,
+	config.TrustedFuncResultNilable: nilableProducer,
+}
+
+// asTrustedFunc converts a user-declared trusted function to the signature and action of the
+// built-in trusted functions.
+func asTrustedFunc(f config.TrustedFunc) (trustedFuncSig, trustedFuncAction) {
+	sig := trustedFuncSig{kind: _func, enclosingRegex: f.Enclosing, funcNameRegex: f.Name}
+	if f.IsMethod {
+		sig.kind = _method
+	}
+	a := trustedFuncAction{action: _userTrustedFuncActions[f.Effect], argIndex: f.Arg}
+	if f.Effect == config.TrustedFuncResultNonnil || f.Effect == config.TrustedFuncResultNilable {
+		a.argIndex = -1
+	}
+	return sig, a
+}
+
 // BuiltinAppend is used to check the builtin append method for slice
 const BuiltinAppend = "append"
 
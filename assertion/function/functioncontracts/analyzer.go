@@ -21,6 +21,7 @@ import (
 	"reflect"
 	"runtime/debug"
 
+	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/config"
 	"golang.org/x/tools/go/analysis"
 )
@@ -32,6 +33,9 @@ type Result struct {
 	// FunctionContractsMap is the map generated from reading the function contracts in the source
 	// code.
 	FunctionContracts Map
+	// Diagnostics is the slice of diagnostics for the malformed or invalid contracts, which are
+	// reported by the upper-level analyzers.
+	Diagnostics []analysis.Diagnostic
 	// Errors is the slice of errors if errors happened during analysis. We put the errors here as
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
 	// to do with them.
@@ -44,7 +48,8 @@ var Analyzer = &analysis.Analyzer{
 	Doc:        _doc,
 	Run:        run,
 	ResultType: reflect.TypeOf((*Result)(nil)).Elem(),
-	Requires:   []*analysis.Analyzer{config.Analyzer},
+	Requires:   []*analysis.Analyzer{config.Analyzer, anonymousfunc.Analyzer},
+	FactTypes:  []analysis.Fact{new(PackageContracts)},
 }
 
 func run(pass *analysis.Pass) (result interface{}, _ error) {
@@ -70,5 +75,10 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		return Result{FunctionContracts: Map{}}, nil
 	}
 
-	return Result{FunctionContracts: collectFunctionContracts(pass)}, nil
+	m, diagnostics := collectFunctionContracts(pass)
+	// Export the contracts of the current package before importing the ones from the upstream
+	// packages, such that only the contracts of the current package are exported.
+	exportContracts(pass, m)
+	importContracts(pass, m)
+	return Result{FunctionContracts: m, Diagnostics: diagnostics}, nil
 }
@@ -22,11 +22,19 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"go.uber.org/nilaway/config"
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
-func TestAnalyzer(t *testing.T) {
-	t.Parallel()
+func TestAnalyzer(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to enable the experimental
+	// contract inference.
+	err := config.Analyzer.Flags.Set(config.ExperimentalContractInferenceFlag, "true")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ExperimentalContractInferenceFlag, "false")
+		require.NoError(t, err)
+	}()
 
 	testdata := analysistest.TestData()
 
@@ -65,11 +73,48 @@ func TestAnalyzer(t *testing.T) {
 			&FunctionContract{Ins: []ContractVal{Any, NonNil}, Outs: []ContractVal{NonNil, True}},
 			&FunctionContract{Ins: []ContractVal{NonNil, Any}, Outs: []ContractVal{NonNil, True}},
 		},
+		getFuncObj("nilToNil"): {
+			&FunctionContract{Ins: []ContractVal{Nil}, Outs: []ContractVal{Nil}},
+		},
+		getFuncObj("boolParam"): {
+			&FunctionContract{Ins: []ContractVal{True}, Outs: []ContractVal{NonNil}},
+		},
+		getFuncObj("errorAware"): {
+			&FunctionContract{Ins: []ContractVal{Any}, Outs: []ContractVal{NonNil, Nil}},
+		},
+		// The functions with malformed or invalid contracts should not exist in the map.
 		// function contractCommentInOtherLine should not exist in the map as it has no contract.
+		getFuncObj("inferredReturnParam"): {
+			&FunctionContract{Ins: []ContractVal{NonNil}, Outs: []ContractVal{NonNil}},
+		},
+		getFuncObj("inferredNilGuard"): {
+			&FunctionContract{Ins: []ContractVal{NonNil}, Outs: []ContractVal{NonNil}},
+		},
+		getFuncObj("inferredElseNilGuard"): {
+			&FunctionContract{Ins: []ContractVal{NonNil}, Outs: []ContractVal{NonNil}},
+		},
+		getFuncObj("inferredViaCall"): {
+			&FunctionContract{Ins: []ContractVal{NonNil}, Outs: []ContractVal{NonNil}},
+		},
+		// The functions named "notInferred*" should not exist in the map as their contracts cannot
+		// be inferred.
 	}
 	if diff := cmp.Diff(expectedNameToContracts, actualNameToContracts); diff != "" {
 		require.Fail(t, fmt.Sprintf("parsed contracts mismatch (-want +got):\n%s", diff))
 	}
+
+	actualDiagnostics := make([]string, 0, len(result.(Result).Diagnostics))
+	for _, d := range result.(Result).Diagnostics {
+		actualDiagnostics = append(actualDiagnostics, d.Message)
+	}
+	expectedDiagnostics := []string{
+		"Malformed function contract \"contract(nonnil => nonnil)\": expecting `contract(VALUE, ... -> VALUE, ...)`",
+		"Invalid function contract: unknown value \"nilable\" (expecting one of nonnil, nil, true, false or _)",
+		"Invalid function contract `contract(nonnil -> nonnil, true)`: expecting 1 output values for the results, got 2",
+		"Invalid function contract `contract(nonnil -> true)`: value \"true\" for result 0 of non-bool type \"*int\"",
+		"Invalid function contract `contract(nil -> nonnil)`: value \"nil\" for param 0 of non-nilable type \"int\"",
+	}
+	require.Equal(t, expectedDiagnostics, actualDiagnostics)
 }
 
 func TestMain(m *testing.M) {
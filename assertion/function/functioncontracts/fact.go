@@ -0,0 +1,83 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functioncontracts
+
+import (
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/objectpath"
+)
+
+// PackageContracts is the package fact storing the contracts (hand-written or inferred) of the
+// functions of a package, keyed by the object paths of the functions, such that the contracts can
+// be applied at the call sites in the downstream packages as well. Note that only the functions
+// that are reachable from the package scope (e.g., package-level functions and methods of
+// package-level types) have object paths and can therefore be exported.
+type PackageContracts struct {
+	Contracts map[objectpath.Path][]*FunctionContract
+}
+
+// AFact enables use of the facts passing mechanism in Go's analysis framework.
+func (*PackageContracts) AFact() {}
+
+// exportContracts exports the contracts of the functions declared in the current package as a
+// package fact, if there are any.
+func exportContracts(pass *analysis.Pass, m Map) {
+	encoder := &objectpath.Encoder{}
+	contracts := make(map[objectpath.Path][]*FunctionContract)
+	for funcObj, funcContracts := range m {
+		if funcObj.Pkg() != pass.Pkg {
+			continue
+		}
+		path, err := encoder.For(funcObj)
+		if err != nil {
+			// The function cannot be referred to by downstream packages.
+			continue
+		}
+		contracts[path] = funcContracts
+	}
+	if len(contracts) > 0 {
+		pass.ExportPackageFact(&PackageContracts{Contracts: contracts})
+	}
+}
+
+// importContracts stores the contracts of the functions from the upstream packages in the map.
+func importContracts(pass *analysis.Pass, m Map) {
+	for _, fact := range pass.AllPackageFacts() {
+		pkgContracts, ok := fact.Fact.(*PackageContracts)
+		if !ok || fact.Package == pass.Pkg {
+			continue
+		}
+		// Sort the paths for deterministic iteration order.
+		paths := make([]objectpath.Path, 0, len(pkgContracts.Contracts))
+		for path := range pkgContracts.Contracts {
+			paths = append(paths, path)
+		}
+		sort.Slice(paths, func(i, j int) bool { return paths[i] < paths[j] })
+
+		for _, path := range paths {
+			obj, err := objectpath.Object(fact.Package, path)
+			if err != nil {
+				// The function is not visible in the current package, e.g., the type declaring
+				// the method is not referenced by the current package.
+				continue
+			}
+			if funcObj, ok := obj.(*types.Func); ok {
+				m[funcObj] = pkgContracts.Contracts[path]
+			}
+		}
+	}
+}
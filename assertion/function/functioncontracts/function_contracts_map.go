@@ -21,7 +21,9 @@ import (
 	"regexp"
 	"strings"
 
+	"go.uber.org/nilaway/assertion/anonymousfunc"
 	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
 )
 
@@ -31,6 +33,8 @@ type ContractVal string
 const (
 	// NonNil has keyword "nonnil".
 	NonNil ContractVal = "nonnil"
+	// Nil has keyword "nil".
+	Nil ContractVal = "nil"
 	// False has keyword "false".
 	False ContractVal = "false"
 	// True has keyword "true".
@@ -39,28 +43,32 @@ const (
 	Any ContractVal = "_"
 )
 
-// stringToContractVal converts a keyword string into the corresponding function ContractVal.
-func stringToContractVal(keyword string) ContractVal {
+// stringToContractVal converts a keyword string into the corresponding function ContractVal, or
+// returns an error if the keyword is unknown.
+func stringToContractVal(keyword string) (ContractVal, error) {
 	switch keyword {
 	case "nonnil":
-		return NonNil
+		return NonNil, nil
+	case "nil":
+		return Nil, nil
 	case "false":
-		return False
+		return False, nil
 	case "true":
-		return True
+		return True, nil
 	case "_":
-		return Any
+		return Any, nil
 	default:
-		// TODO: The ideal way to handle this is to keep track of this contract parsing error and
-		//  move on to the other contracts. But this may also require some refactoring of other
-		//  parts (we do not currently handle partial recoveries anyways)
-		panic("Unexpected keyword for ContractVal: " + keyword)
+		return "", fmt.Errorf("unknown value %q (expecting one of %s, %s, %s, %s or %s)",
+			keyword, NonNil, Nil, True, False, Any)
 	}
 }
 
 const _sep = ","
 const _contractKeyword = "contract"
-const _contractValKeyword = NonNil + "|" + False + "|" + True + "|" + Any
+
+// _contractValRE matches a single contract value. Any word is matched here such that the unknown
+// keywords can be reported by stringToContractVal instead of silently ignoring the contract.
+const _contractValRE = "\\w+"
 
 // _contractRE matches multiple function contracts in the same line. Each contract looks like
 // `contract(VALUE(,VALUE)+ -> VALUE(,VALUE)+)`. The RE also captures two lists of VALUEs,
@@ -70,75 +78,254 @@ const _contractValKeyword = NonNil + "|" + False + "|" + True + "|" + Any
 // acknowledge only the contracts written in their own line.
 var _contractRE = regexp.MustCompile(
 	fmt.Sprintf("^\\s*//\\s*(?:\\s*%s\\s*\\(\\s*((?:%s)(?:\\s*,\\s*(?:%s))*)\\s*->\\s*((?:%s)(?:\\s*,\\s*(?:%s))*)\\s*\\)\\s*)+$",
-		_contractKeyword, _contractValKeyword, _contractValKeyword, _contractValKeyword, _contractValKeyword))
+		_contractKeyword, _contractValRE, _contractValRE, _contractValRE, _contractValRE))
+
+// _contractLikeRE matches the lines that are meant to be function contracts, i.e., the ones
+// starting with `contract(` and ending with `)`. Such lines not matched by _contractRE are
+// reported as malformed contracts.
+var _contractLikeRE = regexp.MustCompile(fmt.Sprintf("^\\s*//\\s*%s\\s*\\(.*\\)\\s*$", _contractKeyword))
 
-// FunctionContract represents a function contract.
+// FunctionContract represents a function contract `contract(Ins -> Outs)`, which states that if
+// the arguments of a call satisfy the values in Ins, the results of the call satisfy the values in
+// Outs. `_` matches any value, `nonnil` and `nil` the (non-)nilness of a value of a nilable type,
+// and `true` and `false` the value of a bool.
+//
+// For a function whose last result is an `error` or a `bool` (see util.FuncIsErrReturning and
+// util.FuncIsOkReturning), `nil` or `true` respectively for the last result serves as the
+// condition for the other results instead, e.g., `contract(_ -> nonnil, nil)` states that the
+// first result is nonnil whenever the error is nil, which is only guaranteed after checking the
+// error at the call site.
 type FunctionContract struct {
 	Ins  []ContractVal
 	Outs []ContractVal
 }
 
+// IsNonNilToNonNil returns true if the contract is `contract(nonnil -> nonnil)`.
+func (c *FunctionContract) IsNonNilToNonNil() bool {
+	return len(c.Ins) == 1 && c.Ins[0] == NonNil && len(c.Outs) == 1 && c.Outs[0] == NonNil
+}
+
+// String returns the contract as written in the doc comments.
+func (c *FunctionContract) String() string {
+	join := func(vals []ContractVal) string {
+		strs := make([]string, len(vals))
+		for i, v := range vals {
+			strs[i] = string(v)
+		}
+		return strings.Join(strs, _sep+" ")
+	}
+	return fmt.Sprintf("%s(%s -> %s)", _contractKeyword, join(c.Ins), join(c.Outs))
+}
+
 // Map stores the mappings from *types.Func to associated function contracts.
 type Map map[*types.Func][]*FunctionContract
 
+// HasOnlyNonNilToNonNil returns true if the function has only one contract that is
+// `contract(nonnil -> nonnil)`, which is applied by duplicating the triggers of the function at
+// its call sites (instead of being evaluated at the call sites as the other contracts).
+func (m Map) HasOnlyNonNilToNonNil(funcObj *types.Func) bool {
+	contracts, ok := m[funcObj]
+	if !ok || len(contracts) != 1 {
+		return false
+	}
+	return contracts[0].IsNonNilToNonNil()
+}
+
 // collectFunctionContracts collects all the function contracts and returns a map that associates
-// every function with its contracts if it has any. One function can have multiple contracts.
-func collectFunctionContracts(pass *analysis.Pass) Map {
+// every function with its contracts if it has any, along with the diagnostics for the malformed
+// or invalid contracts (which are dropped). One function can have multiple contracts. The
+// contracts are read from the doc comments of the functions, methods and interface methods, and
+// from the comments right above the function literals (see funcLitDoc), where the function
+// literals are keyed by their fake function objects (see anonymousfunc.FuncLitInfo). The
+// hand-written contracts take precedence, and the contracts of the other functions are inferred
+// from their bodies if possible (see inferContracts) when the experimental contract inference is
+// enabled.
+func collectFunctionContracts(pass *analysis.Pass) (Map, []analysis.Diagnostic) {
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
+	funcLitMap := pass.ResultOf[anonymousfunc.Analyzer].(anonymousfunc.Result).FuncLitMap
 
 	m := Map{}
+	var (
+		funcDecls   []*ast.FuncDecl
+		diagnostics []analysis.Diagnostic
+	)
+	collect := func(funcObj *types.Func, doc *ast.CommentGroup, sig *types.Signature) {
+		if doc == nil {
+			return
+		}
+		funcContracts, ds := parseContractsForSingleFunction(doc, sig)
+		diagnostics = append(diagnostics, ds...)
+		if len(funcContracts) != 0 {
+			m[funcObj] = funcContracts
+		}
+	}
+
 	for _, file := range pass.Files {
 		if !conf.IsFileInScope(file) {
 			continue
 		}
+		funcDeclDocs := make(map[*ast.CommentGroup]bool)
 		for _, decl := range file.Decls {
-			funcDecl, ok := decl.(*ast.FuncDecl)
-			if !ok || funcDecl.Doc == nil {
-				// Ignore any non-function declaration or the function has no comment
-				// TODO: If we want to support contracts for anonymous function (function
-				//  literals) in the future, then we need to handle more types here.
-				continue
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				funcDecls = append(funcDecls, decl)
+				funcDeclDocs[decl.Doc] = true
+				funcObj := pass.TypesInfo.ObjectOf(decl.Name).(*types.Func)
+				collect(funcObj, decl.Doc, funcObj.Type().(*types.Signature))
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					iface, ok := typeSpec.Type.(*ast.InterfaceType)
+					if !ok {
+						continue
+					}
+					for _, method := range iface.Methods.List {
+						// Embedded interfaces do not have names.
+						if len(method.Names) != 1 {
+							continue
+						}
+						if funcObj, ok := pass.TypesInfo.ObjectOf(method.Names[0]).(*types.Func); ok {
+							collect(funcObj, method.Doc, funcObj.Type().(*types.Signature))
+						}
+					}
+				}
 			}
-			funcObj := pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func)
-			if funcContracts := parseContractsForSingleFunction(funcDecl.Doc); len(funcContracts) != 0 {
-				m[funcObj] = funcContracts
+		}
+
+		// The function literals are only analyzed as functions if the anonymous function support
+		// is enabled, i.e., the map is empty otherwise. We traverse the file instead of the map
+		// for a stable order of the diagnostics.
+		ast.Inspect(file, func(node ast.Node) bool {
+			funcLit, ok := node.(*ast.FuncLit)
+			if !ok {
+				return true
+			}
+			if info, ok := funcLitMap[funcLit]; ok {
+				collect(info.FakeFuncObj, funcLitDoc(pass, file, funcLit, funcDeclDocs), pass.TypesInfo.TypeOf(funcLit).(*types.Signature))
 			}
+			return true
+		})
+	}
+	if conf.ExperimentalContractInferenceEnable {
+		inferContracts(pass, funcDecls, m)
+	}
+	return m, diagnostics
+}
+
+// funcLitDoc returns the comment group ending on the line right above the function literal, which
+// serves as the doc comment of the function literal (e.g., for `f := func(...) {...}`), or nil if
+// there is none. The doc comments of the function declarations are excluded, since they end right
+// above the function literals written in the first line of the declarations.
+func funcLitDoc(pass *analysis.Pass, file *ast.File, funcLit *ast.FuncLit, funcDeclDocs map[*ast.CommentGroup]bool) *ast.CommentGroup {
+	line := pass.Fset.Position(funcLit.Pos()).Line
+	for _, group := range file.Comments {
+		if pass.Fset.Position(group.End()).Line == line-1 && !funcDeclDocs[group] {
+			return group
 		}
 	}
-	return m
+	return nil
 }
 
-// parseContractsForSingleFunction parses a slice of function contracts from a singe comment group.
-// If no contract is found from the comment group, an empty slice is returned.
-func parseContractsForSingleFunction(doc *ast.CommentGroup) []*FunctionContract {
+// parseContractsForSingleFunction parses a slice of function contracts from a singe comment group,
+// and validates them against the signature of the function. If no contract is found from the
+// comment group, an empty slice is returned. The contracts that cannot be parsed or do not fit
+// the signature are reported in the returned diagnostics instead.
+func parseContractsForSingleFunction(doc *ast.CommentGroup, sig *types.Signature) ([]*FunctionContract, []analysis.Diagnostic) {
 	contracts := make([]*FunctionContract, 0)
+	var diagnostics []analysis.Diagnostic
+	report := func(lineComment *ast.Comment, format string, args ...any) {
+		diagnostics = append(diagnostics, analysis.Diagnostic{
+			Pos:     lineComment.Pos(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
 	for _, lineComment := range doc.List {
 		res := _contractRE.FindAllStringSubmatch(lineComment.Text, -1)
 		if res == nil {
+			if _contractLikeRE.MatchString(lineComment.Text) {
+				report(lineComment, "Malformed function contract %q: expecting `%s(VALUE, ... -> VALUE, ...)`",
+					strings.TrimSpace(strings.TrimPrefix(lineComment.Text, "//")), _contractKeyword)
+			}
 			continue
 		}
 		for _, matching := range res {
 			// matching is a slice of three elements; the first is the whole matched string and the
 			// next two are the captured groups of contract values before and after `->`.
-			ins := parseListOfContractValues(matching[1])
-			outs := parseListOfContractValues(matching[2])
+			ins, err := parseListOfContractValues(matching[1])
+			if err != nil {
+				report(lineComment, "Invalid function contract: %v", err)
+				continue
+			}
+			outs, err := parseListOfContractValues(matching[2])
+			if err != nil {
+				report(lineComment, "Invalid function contract: %v", err)
+				continue
+			}
 			ctrt := &FunctionContract{
 				Ins:  ins,
 				Outs: outs,
 			}
+			if err := validateContract(ctrt, sig); err != nil {
+				report(lineComment, "Invalid function contract `%s`: %v", ctrt, err)
+				continue
+			}
 			contracts = append(contracts, ctrt)
 		}
 	}
-	return contracts
+	return contracts, diagnostics
 }
 
 // parseListOfContractValues splits a string of comma separated contract value keywords and returns
-// a slice of ContractVal.
-func parseListOfContractValues(wholeStr string) []ContractVal {
+// a slice of ContractVal, or an error if any of the keywords is unknown.
+func parseListOfContractValues(wholeStr string) ([]ContractVal, error) {
 	valKeywords := strings.Split(wholeStr, _sep)
 	contractVals := make([]ContractVal, len(valKeywords))
 	for i, v := range valKeywords {
-		contractVals[i] = stringToContractVal(strings.TrimSpace(v))
+		val, err := stringToContractVal(strings.TrimSpace(v))
+		if err != nil {
+			return nil, err
+		}
+		contractVals[i] = val
+	}
+	return contractVals, nil
+}
+
+// validateContract returns an error if the contract does not fit the signature of the function,
+// i.e., the numbers of the values do not match the numbers of the params and results, or a value
+// cannot be taken by the type of the corresponding param or result.
+func validateContract(ctrt *FunctionContract, sig *types.Signature) error {
+	if len(ctrt.Ins) != sig.Params().Len() {
+		return fmt.Errorf("expecting %d input values for the params, got %d", sig.Params().Len(), len(ctrt.Ins))
+	}
+	if len(ctrt.Outs) != sig.Results().Len() {
+		return fmt.Errorf("expecting %d output values for the results, got %d", sig.Results().Len(), len(ctrt.Outs))
+	}
+	check := func(val ContractVal, v *types.Var, site string) error {
+		switch val {
+		case NonNil, Nil:
+			if util.TypeBarsNilness(v.Type()) {
+				return fmt.Errorf("value %q for %s of non-nilable type %q", val, site, v.Type())
+			}
+		case True, False:
+			if b, ok := v.Type().Underlying().(*types.Basic); !ok || b.Info()&types.IsBoolean == 0 {
+				return fmt.Errorf("value %q for %s of non-bool type %q", val, site, v.Type())
+			}
+		}
+		return nil
+	}
+	for i, val := range ctrt.Ins {
+		if err := check(val, sig.Params().At(i), fmt.Sprintf("param %d", i)); err != nil {
+			return err
+		}
+	}
+	for i, val := range ctrt.Outs {
+		if err := check(val, sig.Results().At(i), fmt.Sprintf("result %d", i)); err != nil {
+			return err
+		}
 	}
-	return contractVals
+	return nil
 }
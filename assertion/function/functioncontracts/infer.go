@@ -0,0 +1,206 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the
+// License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functioncontracts
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+)
+
+// inferContracts infers the contract `contract(nonnil -> nonnil)` for the functions without
+// hand-written contracts from their bodies (see inferNonNilToNonNil), and stores the inferred
+// contracts in the map. Since the inference of a function may depend on the contracts of the
+// functions it calls (e.g., `return g(x)`), the inference is repeated until no more contracts can
+// be inferred.
+func inferContracts(pass *analysis.Pass, funcDecls []*ast.FuncDecl, m Map) {
+	for changed := true; changed; {
+		changed = false
+		for _, funcDecl := range funcDecls {
+			funcObj, ok := pass.TypesInfo.ObjectOf(funcDecl.Name).(*types.Func)
+			if !ok {
+				continue
+			}
+			if _, ok := m[funcObj]; ok {
+				continue
+			}
+			if inferNonNilToNonNil(pass, funcDecl, funcObj, m) {
+				m[funcObj] = []*FunctionContract{{Ins: []ContractVal{NonNil}, Outs: []ContractVal{NonNil}}}
+				changed = true
+			}
+		}
+	}
+}
+
+// inferNonNilToNonNil returns true if the function (with a single param and a single result, both
+// of nilable types) is known to satisfy `contract(nonnil -> nonnil)`, i.e., each return statement
+// returns, unless the param is known to be nil at that point (e.g., in the body of
+// `if x == nil {...}`), one of the following:
+//   - the param itself;
+//   - a value that is trivially nonnil, e.g., `&T{}`, `new(T)` or `make([]T, n)`;
+//   - the result of calling a function with the same contract on one of the above.
+//
+// The inference is deliberately syntactic and conservative: the function is not considered if
+// the param is ever reassigned or its address is taken, and any other return value (e.g., a
+// field of the param) fails the inference. Moreover, functions with hand-written nilability
+// annotations are not considered either, since the annotations must hold for all calls, while
+// the contracts make the results of the calls depend on the arguments of the calls instead.
+func inferNonNilToNonNil(pass *analysis.Pass, funcDecl *ast.FuncDecl, funcObj *types.Func, m Map) bool {
+	sig := funcObj.Type().(*types.Signature)
+	if funcDecl.Body == nil || sig.Variadic() || sig.Params().Len() != 1 || sig.Results().Len() != 1 {
+		return false
+	}
+	if funcDecl.Doc != nil && annotation.HasNilabilityAnnotations(funcDecl.Doc) {
+		return false
+	}
+	param := sig.Params().At(0)
+	if param.Name() == "" || param.Name() == "_" ||
+		util.TypeBarsNilness(param.Type()) || util.TypeBarsNilness(sig.Results().At(0).Type()) {
+		return false
+	}
+
+	c := &contractInferrer{pass: pass, param: param, contracts: m}
+	if c.paramModified(funcDecl.Body) {
+		return false
+	}
+	return c.checkStmts(funcDecl.Body.List, false /* paramNil */) && c.hasReturn
+}
+
+// contractInferrer holds the state for inferring the contract of a single function.
+type contractInferrer struct {
+	pass      *analysis.Pass
+	param     *types.Var
+	contracts Map
+	// hasReturn indicates whether any return statement has been checked.
+	hasReturn bool
+}
+
+// isParam returns true if the expression is the param.
+func (c *contractInferrer) isParam(expr ast.Expr) bool {
+	ident, ok := util.StripParens(expr).(*ast.Ident)
+	return ok && c.pass.TypesInfo.ObjectOf(ident) == c.param
+}
+
+// paramModified returns true if the param is reassigned or has its address taken anywhere in the
+// body, including the function literals capturing it.
+func (c *contractInferrer) paramModified(body *ast.BlockStmt) bool {
+	modified := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				modified = modified || c.isParam(lhs)
+			}
+		case *ast.RangeStmt:
+			modified = modified || (node.Key != nil && c.isParam(node.Key)) || (node.Value != nil && c.isParam(node.Value))
+		case *ast.UnaryExpr:
+			modified = modified || (node.Op == token.AND && c.isParam(node.X))
+		}
+		return !modified
+	})
+	return modified
+}
+
+// checkStmts returns true if all return statements in the statements satisfy the contract (see
+// inferNonNilToNonNil), where paramNil indicates whether the param is known to be nil.
+func (c *contractInferrer) checkStmts(stmts []ast.Stmt, paramNil bool) bool {
+	for _, stmt := range stmts {
+		if !c.checkStmt(stmt, paramNil) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkStmt returns true if all return statements in the statement satisfy the contract (see
+// inferNonNilToNonNil), where paramNil indicates whether the param is known to be nil.
+func (c *contractInferrer) checkStmt(stmt ast.Stmt, paramNil bool) bool {
+	switch stmt := stmt.(type) {
+	case *ast.ReturnStmt:
+		c.hasReturn = true
+		// Naked returns of named results are not supported.
+		if len(stmt.Results) != 1 {
+			return false
+		}
+		return paramNil || c.isNonNilIfParamNonNil(stmt.Results[0])
+	case *ast.BlockStmt:
+		return c.checkStmts(stmt.List, paramNil)
+	case *ast.LabeledStmt:
+		return c.checkStmt(stmt.Stmt, paramNil)
+	case *ast.IfStmt:
+		thenNil, elseNil := paramNil, paramNil
+		if binExpr, ok := util.StripParens(stmt.Cond).(*ast.BinaryExpr); ok &&
+			(c.isParam(binExpr.X) && util.IsLiteral(binExpr.Y, "nil") || util.IsLiteral(binExpr.X, "nil") && c.isParam(binExpr.Y)) {
+			switch binExpr.Op {
+			case token.EQL:
+				thenNil = true
+			case token.NEQ:
+				elseNil = true
+			}
+		}
+		return c.checkStmt(stmt.Body, thenNil) && (stmt.Else == nil || c.checkStmt(stmt.Else, elseNil))
+	case *ast.ForStmt:
+		return c.checkStmt(stmt.Body, paramNil)
+	case *ast.RangeStmt:
+		return c.checkStmt(stmt.Body, paramNil)
+	case *ast.SwitchStmt:
+		return c.checkStmt(stmt.Body, paramNil)
+	case *ast.TypeSwitchStmt:
+		return c.checkStmt(stmt.Body, paramNil)
+	case *ast.SelectStmt:
+		return c.checkStmt(stmt.Body, paramNil)
+	case *ast.CaseClause:
+		return c.checkStmts(stmt.Body, paramNil)
+	case *ast.CommClause:
+		return c.checkStmts(stmt.Body, paramNil)
+	}
+	// The other statements cannot contain return statements (other than those in function
+	// literals, which do not return from the function).
+	return true
+}
+
+// isNonNilIfParamNonNil returns true if the expression is known to be nonnil given that the param
+// is nonnil (see inferNonNilToNonNil).
+func (c *contractInferrer) isNonNilIfParamNonNil(expr ast.Expr) bool {
+	expr = util.StripParens(expr).(ast.Expr)
+	if c.isParam(expr) {
+		return true
+	}
+	switch expr := expr.(type) {
+	case *ast.UnaryExpr:
+		return expr.Op == token.AND
+	case *ast.CompositeLit, *ast.FuncLit, *ast.BasicLit:
+		return true
+	case *ast.CallExpr:
+		ident := util.FuncIdentFromCallExpr(expr)
+		if ident == nil {
+			return false
+		}
+		switch obj := c.pass.TypesInfo.ObjectOf(ident).(type) {
+		case *types.Builtin:
+			return obj.Name() == "new" || obj.Name() == "make"
+		case *types.Func:
+			contracts, ok := c.contracts[obj]
+			if !ok || len(contracts) != 1 || len(expr.Args) != 1 {
+				return false
+			}
+			return contracts[0].IsNonNilToNonNil() && c.isNonNilIfParamNonNil(expr.Args[0])
+		}
+	}
+	return false
+}
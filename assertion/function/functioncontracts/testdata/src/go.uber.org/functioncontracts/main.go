@@ -60,7 +60,115 @@ func multipleContracts(x *int, y *int) (*int, bool) {
 	return new(int), true
 }
 
+// contract(nil -> nil)
+func nilToNil(x *int) *int {
+	if x == nil {
+		return nil
+	}
+	return new(int)
+}
+
+// contract(true -> nonnil)
+func boolParam(must bool) *int {
+	if must {
+		return new(int)
+	}
+	return nil
+}
+
+// contract(_ -> nonnil, nil)
+func errorAware(s string) (*int, error) {
+	return new(int), nil
+}
+
+// The contracts of the functions below are reported as malformed or invalid, and dropped.
+
+// contract(nonnil => nonnil)
+func malformed(x *int) *int {
+	return nil
+}
+
+// contract(nilable -> nonnil)
+func unknownValue(x *int) *int {
+	return nil
+}
+
+// contract(nonnil -> nonnil, true)
+func wrongArity(x *int) *int {
+	return nil
+}
+
+// contract(nonnil -> true)
+func nonBoolResult(x *int) *int {
+	return nil
+}
+
+// contract(nil -> nonnil)
+func nonNilableParam(x int) *int {
+	return nil
+}
+
 // This contract `// contract(nonnil -> nonnil)` does not hold for the function because the
 // function has no param or return. Only a contract in its own line should be parsed, not even `//
 // contract(nonnil -> nonnil)`.
 func contractCommentInOtherLine() {}
+
+type S struct {
+	f *int
+}
+
+// The contracts of the functions below are inferred from the function bodies.
+
+func inferredReturnParam(x *int) *int {
+	return x
+}
+
+func inferredNilGuard(x *int) *S {
+	if x == nil {
+		return nil
+	}
+	return &S{f: x}
+}
+
+func inferredElseNilGuard(x *int) []int {
+	if x != nil {
+		return make([]int, *x)
+	} else {
+		return nil
+	}
+}
+
+// inferredViaCall is declared before the function it calls to test that the inference is
+// repeated until no more contracts can be inferred.
+func inferredViaCall(x *int) *int {
+	return inferredReturnParam(f1(x))
+}
+
+func notInferredField(x *S) *int {
+	return x.f
+}
+
+func notInferredReassigned(x *int) *int {
+	x = nil
+	return x
+}
+
+func notInferredReturnsNil(x *int) *int {
+	if x != nil {
+		return nil
+	}
+	return x
+}
+
+func notInferredTwoParams(x *int, y *int) *int {
+	return x
+}
+
+func notInferredNonNilableParam(x int) *int {
+	return &x
+}
+
+// nilable(x)
+func notInferredAnnotated(x *int) *int {
+	return x
+}
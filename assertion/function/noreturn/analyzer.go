@@ -0,0 +1,91 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package noreturn implements a sub-analyzer that finds the calls to the functions that never
+// return but are not known to the ctrlflow analysis (e.g., `t.Fatal`), such that the code
+// following them can be treated as unreachable by the function analyzer. The calls to the other
+// functions that never return (e.g., `panic`, `os.Exit`, `log.Fatal`, and the functions whose
+// bodies never return) already end the blocks in the CFGs built by the ctrlflow analysis.
+package noreturn
+
+import (
+	"fmt"
+	"go/ast"
+	"reflect"
+	"runtime/debug"
+
+	"go.uber.org/nilaway/config"
+	"golang.org/x/tools/go/analysis"
+)
+
+const _doc = "Find the calls to the functions that never return but are not known to the ctrlflow " +
+	"analysis, i.e., the `Fatal*`, `FailNow` and `Skip*` methods of `testing.TB` and the functions " +
+	"declared to never return in the config."
+
+// Result is the result struct for the Analyzer.
+type Result struct {
+	// Calls is the set of the call expressions (in the files in scope) to the functions that never
+	// return but are not known to the ctrlflow analysis. This field will always be nonnil even if
+	// the package is not in scope.
+	Calls map[*ast.CallExpr]bool
+	// Errors is the slice of errors if errors happened during analysis. We put the errors here as
+	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
+	// to do with them.
+	Errors []error
+}
+
+// Analyzer finds the calls to the functions that never return in the package.
+var Analyzer = &analysis.Analyzer{
+	Name:       "nilaway_no_return_analyzer",
+	Doc:        _doc,
+	Run:        run,
+	ResultType: reflect.TypeOf((*Result)(nil)).Elem(),
+	Requires:   []*analysis.Analyzer{config.Analyzer},
+}
+
+func run(pass *analysis.Pass) (result interface{}, _ error) {
+	// As a last resort, we recover from a panic when running the analyzer, convert the panic to
+	// an error and return.
+	defer func() {
+		if r := recover(); r != nil {
+			// Deferred functions are executed after a result is generated, so here we modify the
+			// return value `result` in-place.
+			e := fmt.Errorf("INTERNAL PANIC: %s\n%s", r, string(debug.Stack()))
+			if retResult, ok := result.(Result); ok {
+				retResult.Errors = append(retResult.Errors, e)
+			} else {
+				result = Result{Calls: map[*ast.CallExpr]bool{}, Errors: []error{e}}
+			}
+		}
+	}()
+
+	conf := pass.ResultOf[config.Analyzer].(*config.Config)
+
+	calls := make(map[*ast.CallExpr]bool)
+	if !conf.IsPkgInScope(pass.Pkg) {
+		return Result{Calls: calls}, nil
+	}
+	for _, file := range pass.Files {
+		if !conf.IsFileInScope(file) {
+			continue
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			if call, ok := node.(*ast.CallExpr); ok && isNoReturnCall(pass.TypesInfo, conf, call) {
+				calls[call] = true
+			}
+			return true
+		})
+	}
+	return Result{Calls: calls}, nil
+}
@@ -0,0 +1,49 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package noreturn
+
+import (
+	"go/ast"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+
+	r := analysistest.Run(t, testdata, Analyzer, "go.uber.org/noreturn")
+	require.Equal(t, 1, len(r))
+	require.NotNil(t, r[0])
+	require.IsType(t, Result{}, r[0].Result)
+	result := r[0].Result.(Result)
+	require.Empty(t, result.Errors)
+
+	// Only the calls to the no-return methods of `testing.TB` are found, while the calls to the
+	// functions known to the ctrlflow analysis are not.
+	var calls []string
+	for call := range result.Calls {
+		calls = append(calls, call.Fun.(*ast.SelectorExpr).Sel.Name)
+	}
+	require.ElementsMatch(t, []string{"Fatal", "Fatalf", "FailNow", "SkipNow", "Skipf", "Fatal"}, calls)
+}
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
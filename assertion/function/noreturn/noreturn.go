@@ -0,0 +1,63 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package noreturn
+
+import (
+	"go/ast"
+	"go/types"
+	"regexp"
+
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// _testingNoReturnMethods matches the names of the methods of `testing.TB` that never return. The
+// same methods of `*testing.T` and `*testing.B` are known to the ctrlflow analysis, but the calls
+// to the interface methods are not statically resolved by it.
+var _testingNoReturnMethods = regexp.MustCompile(`^(Fatal(f)?|FailNow|Skip(f|Now)?)$`)
+
+// isNoReturnCall returns true if the call is to a function that never returns but is not known to
+// the ctrlflow analysis, i.e., a method of `testing.TB` in _testingNoReturnMethods or a function
+// declared by the users in the config (see config.TrustedFuncNoReturn).
+func isNoReturnCall(info *types.Info, conf *config.Config, call *ast.CallExpr) bool {
+	funcObj, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || funcObj.Pkg() == nil {
+		return false
+	}
+	funcObj = funcObj.Origin()
+
+	enclosing := funcObj.Pkg().Path()
+	recv := funcObj.Type().(*types.Signature).Recv()
+	if recv != nil {
+		named, ok := util.UnwrapPtr(recv.Type()).(*types.Named)
+		if !ok {
+			return false
+		}
+		enclosing += "." + named.Obj().Name()
+	}
+
+	if enclosing == "testing.TB" &&
+		_testingNoReturnMethods.MatchString(funcObj.Name()) {
+		return true
+	}
+	for _, f := range conf.TrustedFuncs() {
+		if f.Effect == config.TrustedFuncNoReturn && f.IsMethod == (recv != nil) &&
+			f.Enclosing.MatchString(enclosing) && f.Name.MatchString(funcObj.Name()) {
+			return true
+		}
+	}
+	return false
+}
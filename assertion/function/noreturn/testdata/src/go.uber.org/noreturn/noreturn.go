@@ -0,0 +1,53 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package noreturn tests finding the calls to the functions that never return but are not known to
+// the ctrlflow analysis.
+package noreturn
+
+import (
+	"log"
+	"os"
+	"testing"
+)
+
+func testingMethods(t *testing.T, b *testing.B, tb testing.TB) {
+	tb.Fatal("fatal")
+	tb.Fatalf("fatal")
+	tb.FailNow()
+	tb.SkipNow()
+	tb.Skipf("skip")
+	// The methods of `*testing.T` and `*testing.B` are known to the ctrlflow analysis.
+	t.Fatal("fatal")
+	b.Fatalf("fatal")
+	// The other methods may return.
+	tb.Error("error")
+	tb.Fail()
+	tb.Log("log")
+}
+
+type T struct{}
+
+func (*T) Fatal(tb testing.TB) {
+	tb.Fatal("fatal")
+}
+
+func others(t *T, tb testing.TB) {
+	// The methods of other types with the same names may return.
+	t.Fatal(tb)
+	// The functions known to the ctrlflow analysis are not included.
+	os.Exit(1)
+	log.Fatal("fatal")
+	panic("panic")
+}
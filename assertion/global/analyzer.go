@@ -21,6 +21,7 @@ import (
 	"go/token"
 	"reflect"
 	"runtime/debug"
+	"time"
 
 	"go.uber.org/nilaway/annotation"
 	"go.uber.org/nilaway/config"
@@ -37,6 +38,8 @@ type Result struct {
 	// part of the result of this sub-analyzer so that the upper-level analyzers can decide what
 	// to do with them.
 	Errors []error
+	// Duration is the wall-clock time spent in the analyzer.
+	Duration time.Duration
 }
 
 // Analyzer checks if the nonnill global variables are initialized.
@@ -64,6 +67,7 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		}
 	}()
 
+	start := time.Now()
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
 
 	if !conf.IsPkgInScope(pass.Pkg) {
@@ -87,5 +91,5 @@ func run(pass *analysis.Pass) (result interface{}, _ error) {
 		}
 	}
 
-	return Result{FullTriggers: fullTriggers}, nil
+	return Result{FullTriggers: fullTriggers, Duration: time.Since(start)}, nil
 }
@@ -0,0 +1,153 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// _baselineVersion is the version of the baseline file format.
+const _baselineVersion = 1
+
+// baseline is the content of a baseline file, which records the fingerprints of the known errors
+// such that only new errors are reported.
+type baseline struct {
+	Version int             `json:"version"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+// baselineEntry is a single entry in the baseline. Besides the fingerprint, it also records the
+// components of the fingerprint for human readers.
+type baselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	// Function is the function enclosing the dereference point.
+	Function string `json:"function"`
+	// Source describes the nil source, i.e., the first step in the nil flow.
+	Source string `json:"source"`
+	// Dereference describes the dereference, i.e., the last step in the nil flow.
+	Dereference string `json:"dereference"`
+	// Count is the number of errors with this fingerprint, since the same nil source can be
+	// dereferenced in the same way multiple times in a function.
+	Count int `json:"count"`
+}
+
+// _positionPattern matches the line and column numbers following a Go file name, which appear
+// in the reprs of some producers and consumers (e.g., assignments) and must be excluded from the
+// fingerprints.
+var _positionPattern = regexp.MustCompile(`(\.go):\d+(:\d+)?`)
+
+// newBaselineEntry creates a baseline entry (with a count of 1) for the diagnostic, and returns
+// false if the diagnostic cannot be fingerprinted (i.e., it is not generated from a conflict).
+func newBaselineEntry(d reportedDiagnostic) (baselineEntry, bool) {
+	if d.Conflict == nil {
+		return baselineEntry{}, false
+	}
+	flow := d.Conflict.Flow()
+	if len(flow) == 0 {
+		return baselineEntry{}, false
+	}
+
+	entry := baselineEntry{
+		Function:    d.Function,
+		Source:      _positionPattern.ReplaceAllString(flow[0].Repr(), "$1"),
+		Dereference: _positionPattern.ReplaceAllString(flow[len(flow)-1].Repr(), "$1"),
+		Count:       1,
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{entry.Function, entry.Source, entry.Dereference}, "\x00")))
+	entry.Fingerprint = hex.EncodeToString(sum[:16])
+	return entry, true
+}
+
+// writeBaseline writes the baseline of the diagnostics to the file.
+func writeBaseline(path string, diagnostics []reportedDiagnostic) error {
+	entries := make(map[string]baselineEntry)
+	for _, d := range diagnostics {
+		entry, ok := newBaselineEntry(d)
+		if !ok {
+			continue
+		}
+		if existing, ok := entries[entry.Fingerprint]; ok {
+			entry.Count += existing.Count
+		}
+		entries[entry.Fingerprint] = entry
+	}
+
+	b := baseline{Version: _baselineVersion, Entries: make([]baselineEntry, 0, len(entries))}
+	for _, entry := range entries {
+		b.Entries = append(b.Entries, entry)
+	}
+	sort.Slice(b.Entries, func(i, j int) bool { return b.Entries[i].Fingerprint < b.Entries[j].Fingerprint })
+
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write baseline: %w", err)
+	}
+	return nil
+}
+
+// readBaseline reads the baseline from the file.
+func readBaseline(path string) (*baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var b baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("decode baseline: %w", err)
+	}
+	if b.Version != _baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d, expecting %d", b.Version, _baselineVersion)
+	}
+	return &b, nil
+}
+
+// filter returns the diagnostics that are not in the baseline, as well as the baseline entries
+// that no longer match any diagnostic (with their counts set to the number of missing errors).
+func (b *baseline) filter(diagnostics []reportedDiagnostic) ([]reportedDiagnostic, []baselineEntry) {
+	remaining := make(map[string]int, len(b.Entries))
+	for _, entry := range b.Entries {
+		remaining[entry.Fingerprint] += entry.Count
+	}
+
+	var kept []reportedDiagnostic
+	for _, d := range diagnostics {
+		if entry, ok := newBaselineEntry(d); ok && remaining[entry.Fingerprint] > 0 {
+			remaining[entry.Fingerprint]--
+			continue
+		}
+		kept = append(kept, d)
+	}
+
+	var disappeared []baselineEntry
+	for _, entry := range b.Entries {
+		if n := remaining[entry.Fingerprint]; n > 0 {
+			entry.Count = n
+			disappeared = append(disappeared, entry)
+			// Avoid reporting duplicate entries (if any) more than once.
+			remaining[entry.Fingerprint] = 0
+		}
+	}
+	return kept, disappeared
+}
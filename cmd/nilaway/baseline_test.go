@@ -0,0 +1,124 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"go/token"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/nilaway/diagnostic"
+)
+
+// newTestDiagnostic creates a diagnostic for a conflict where the nil source flows from line
+// sourceLine to the dereference at line derefLine in the function.
+func newTestDiagnostic(function, source string, sourceLine, derefLine int) reportedDiagnostic {
+	deref := token.Position{Filename: "/work/pkg/foo.go", Line: derefLine, Column: 5}
+	return reportedDiagnostic{
+		Position: deref,
+		Message:  "Potential nil panic detected.\n",
+		Function: function,
+		Conflict: &diagnostic.Conflict{
+			Position: deref,
+			NilPath: []diagnostic.FlowStep{{
+				ProducerPosition: token.Position{Filename: "/work/pkg/foo.go", Line: sourceLine, Column: 9},
+				ProducerRepr:     source,
+				ConsumerPosition: token.Position{Filename: "/work/pkg/foo.go", Line: sourceLine, Column: 2},
+				ConsumerRepr:     "returned from `bar()`",
+			}},
+			NonNilPath: []diagnostic.FlowStep{{
+				ProducerRepr:     "result 0 of `bar()`",
+				ConsumerPosition: deref,
+				ConsumerRepr:     "dereferenced",
+			}},
+		},
+	}
+}
+
+func TestBaselineFingerprint(t *testing.T) {
+	t.Parallel()
+
+	d := newTestDiagnostic("pkg.foo", "literal `nil`", 3, 10)
+	entry, ok := newBaselineEntry(d)
+	require.True(t, ok)
+
+	// Line shifts (in both the positions and the reprs) must not change the fingerprint.
+	shifted := newTestDiagnostic("pkg.foo", "literal `nil`", 13, 20)
+	shiftedEntry, ok := newBaselineEntry(shifted)
+	require.True(t, ok)
+	require.Equal(t, entry.Fingerprint, shiftedEntry.Fingerprint)
+
+	withPos, ok := newBaselineEntry(newTestDiagnostic("pkg.foo", "assigned at foo.go:3:2", 3, 10))
+	require.True(t, ok)
+	withShiftedPos, ok := newBaselineEntry(newTestDiagnostic("pkg.foo", "assigned at foo.go:42:7", 3, 10))
+	require.True(t, ok)
+	require.Equal(t, withPos.Fingerprint, withShiftedPos.Fingerprint)
+	require.Equal(t, "assigned at foo.go returned from `bar()`", withPos.Source)
+
+	// A different function or nil source must change the fingerprint.
+	other, ok := newBaselineEntry(newTestDiagnostic("pkg.baz", "literal `nil`", 3, 10))
+	require.True(t, ok)
+	require.NotEqual(t, entry.Fingerprint, other.Fingerprint)
+	require.NotEqual(t, entry.Fingerprint, withPos.Fingerprint)
+
+	// Diagnostics without conflicts cannot be fingerprinted.
+	_, ok = newBaselineEntry(reportedDiagnostic{Message: "INTERNAL ERROR: foo"})
+	require.False(t, ok)
+}
+
+func TestBaselineFilter(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	known := []reportedDiagnostic{
+		newTestDiagnostic("pkg.foo", "literal `nil`", 3, 10),
+		newTestDiagnostic("pkg.foo", "literal `nil`", 3, 11),
+		newTestDiagnostic("pkg.bar", "literal `nil`", 30, 40),
+		{Message: "INTERNAL ERROR: foo"},
+	}
+	require.NoError(t, writeBaseline(path, known))
+
+	b, err := readBaseline(path)
+	require.NoError(t, err)
+	require.Len(t, b.Entries, 2)
+
+	// One of the two identical errors in pkg.foo is fixed and the error in pkg.bar is gone, while
+	// the errors are shifted and new errors show up.
+	current := []reportedDiagnostic{
+		newTestDiagnostic("pkg.foo", "literal `nil`", 5, 12),
+		newTestDiagnostic("pkg.new", "literal `nil`", 50, 60),
+		{Message: "INTERNAL ERROR: foo"},
+	}
+	kept, disappeared := b.filter(current)
+	require.Equal(t, current[1:], kept)
+	require.Len(t, disappeared, 2)
+	for _, entry := range disappeared {
+		require.Equal(t, 1, entry.Count)
+		require.Contains(t, []string{"pkg.foo", "pkg.bar"}, entry.Function)
+	}
+}
+
+func TestReadBaselineVersion(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, writeBaseline(path, nil))
+	_, err := readBaseline(path)
+	require.NoError(t, err)
+
+	_, err = readBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+}
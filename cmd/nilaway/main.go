@@ -47,8 +47,20 @@ var (
 	_includeErrorsInFiles string
 	// _excludeErrorsInFiles is a driver flag for specifying the list of file prefixes to not report errors.
 	_excludeErrorsInFiles string
+	// _format is a driver flag for specifying the output format of the errors.
+	_format string
+	// _output is a driver flag for specifying the file to write the structured output to.
+	_output string
+	// _baseline is a driver flag for specifying the baseline file of known errors to suppress.
+	_baseline string
+	// _writeBaseline is a driver flag for specifying the baseline file to write all errors to.
+	_writeBaseline string
 )
 
+// _annotateCommand is the command for writing the inferred annotations back into the source, i.e.,
+// `nilaway annotate <packages>`.
+const _annotateCommand = "annotate"
+
 func run(pass *analysis.Pass) (interface{}, error) {
 	// NilAway by default analyzes all packages, including dependencies. Even if specified to
 	// exclude packages from analysis via configurations, NilAway can still report errors on
@@ -85,6 +97,12 @@ func run(pass *analysis.Pass) (interface{}, error) {
 		}
 	}
 
+	// For structured output formats, report the structured diagnostics for the parent process to
+	// collect (see runStructured).
+	if os.Getenv(_structuredOutputEnv) != "" {
+		return nil, reportStructured(pass)
+	}
+
 	// Delegate the real analysis run to the original nilaway analyzer.
 	return nilaway.Analyzer.Run(pass)
 }
@@ -133,5 +151,46 @@ func main() {
 	flag.StringVar(&_includeErrorsInFiles, "include-errors-in-files", wd, "A comma-separated list of file prefixes to report errors, default is current working directory.")
 	flag.StringVar(&_excludeErrorsInFiles, "exclude-errors-in-files", "", "A comma-separated list of file prefixes to exclude from error reporting. This takes precedence over include-errors-in-files.")
 
+	// Add flags for structured output formats. The flags are parsed by the singlechecker for usage
+	// and validation only, since they have to be known before the singlechecker runs.
+	flag.StringVar(&_format, "format", _formatText, fmt.Sprintf("The output format of the errors, one of %q (default), %q (with structured nil flows) or %q (SARIF 2.1.0).", _formatText, _formatJSON, _formatSARIF))
+	flag.StringVar(&_output, "output", "", "The file to write the errors to, default is stdout for structured output formats and stderr for text.")
+
+	// Add flags for the baseline mode, where the known errors recorded in a baseline file are
+	// suppressed such that only new errors are reported.
+	flag.StringVar(&_baseline, "baseline", "", "The baseline file (written by -write-baseline) of known errors to suppress. Baseline entries that no longer match any error are listed on stderr.")
+	flag.StringVar(&_writeBaseline, "write-baseline", "", "The baseline file to write all errors to (instead of reporting them), for later use with -baseline.")
+
+	// The annotate command is a shorthand for running in annotate mode (see config.AnnotateFlag),
+	// where the suggested fixes (i.e., the inferred annotations) are applied to the source.
+	if len(os.Args) > 1 && os.Args[1] == _annotateCommand {
+		os.Args = append([]string{os.Args[0], "-" + config.AnnotateFlag, "-" + config.PrettyPrintFlag + "=false", "-fix"}, os.Args[2:]...)
+	}
+	// Similarly, the explanations in explain mode (see config.ExplainFlag) are not errors, so they
+	// are not pretty-printed as errors unless explicitly requested.
+	if _, ok := lookupFlag(os.Args[1:], config.ExplainFlag); ok {
+		os.Args = append([]string{os.Args[0], "-" + config.PrettyPrintFlag + "=false"}, os.Args[1:]...)
+	}
+
+	if os.Getenv(_structuredOutputEnv) == "" {
+		args := os.Args[1:]
+		format, ok := lookupFlag(args, "format")
+		if !ok {
+			format = _formatText
+		}
+		baselineFile, _ := lookupFlag(args, "baseline")
+		writeBaselineFile, _ := lookupFlag(args, "write-baseline")
+		if format != _formatText || baselineFile != "" || writeBaselineFile != "" {
+			output, _ := lookupFlag(args, "output")
+			os.Exit(runStructured(structuredOptions{
+				format:        format,
+				output:        output,
+				baseline:      baselineFile,
+				writeBaseline: writeBaselineFile,
+				wd:            wd,
+			}))
+		}
+	}
+
 	singlechecker.Main(Analyzer)
 }
@@ -0,0 +1,200 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// The types below model the subset of the [SARIF 2.1.0] format that NilAway emits.
+//
+// [SARIF 2.1.0]: https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool               sarifTool                        `json:"tool"`
+		OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+		Results            []sarifResult                    `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+
+	sarifRule struct {
+		ID               string       `json:"id"`
+		ShortDescription sarifMessage `json:"shortDescription"`
+	}
+
+	sarifResult struct {
+		RuleID           string          `json:"ruleId"`
+		Level            string          `json:"level"`
+		Message          sarifMessage    `json:"message"`
+		Locations        []sarifLocation `json:"locations"`
+		CodeFlows        []sarifCodeFlow `json:"codeFlows,omitempty"`
+		RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		ID               int                    `json:"id,omitempty"`
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		Message          *sarifMessage          `json:"message,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+
+	sarifCodeFlow struct {
+		ThreadFlows []sarifThreadFlow `json:"threadFlows"`
+	}
+
+	sarifThreadFlow struct {
+		Locations []sarifThreadFlowLocation `json:"locations"`
+	}
+
+	sarifThreadFlowLocation struct {
+		Location sarifLocation `json:"location"`
+	}
+)
+
+const (
+	_sarifVersion = "2.1.0"
+	_sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// _sarifRuleID is the ID of the only rule NilAway reports.
+	_sarifRuleID = "nilaway"
+	// _sarifSrcRoot is the URI base ID for the files under the working directory.
+	_sarifSrcRoot = "%SRCROOT%"
+)
+
+// writeSARIF writes the reported diagnostics as a SARIF log to the writer, where the file paths
+// under the working directory wd are written relative to it.
+func writeSARIF(w io.Writer, diagnostics []reportedDiagnostic, wd string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "nilaway",
+			InformationURI: "https://github.com/uber-go/nilaway",
+			Rules: []sarifRule{{
+				ID:               _sarifRuleID,
+				ShortDescription: sarifMessage{Text: "Potential nil panic"},
+			}},
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			_sarifSrcRoot: {URI: (&url.URL{Scheme: "file", Path: filepath.ToSlash(wd) + "/"}).String()},
+		},
+		// Results must be present (even if empty) for a successful run.
+		Results: []sarifResult{},
+	}
+
+	for _, d := range diagnostics {
+		result := sarifResult{
+			RuleID:    _sarifRuleID,
+			Level:     "error",
+			Message:   sarifMessage{Text: strings.TrimSpace(d.Message)},
+			Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocationOf(d.Position, wd)}},
+		}
+
+		if c := d.Conflict; c != nil {
+			// Every step in the nil flow becomes a location in a single thread flow, from the nil
+			// source to the dereference point.
+			var threadFlow sarifThreadFlow
+			for _, step := range c.Flow() {
+				threadFlow.Locations = append(threadFlow.Locations, sarifThreadFlowLocation{
+					Location: sarifLocation{
+						PhysicalLocation: sarifPhysicalLocationOf(step.Position(), wd),
+						Message:          &sarifMessage{Text: step.Repr()},
+					},
+				})
+			}
+			if len(threadFlow.Locations) > 0 {
+				result.CodeFlows = []sarifCodeFlow{{ThreadFlows: []sarifThreadFlow{threadFlow}}}
+			}
+
+			for i, p := range c.SimilarPositions {
+				result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+					// IDs of related locations must be non-negative and unique within a result.
+					ID:               i + 1,
+					PhysicalLocation: sarifPhysicalLocationOf(p, wd),
+					Message:          &sarifMessage{Text: "Same nil source could also cause potential nil panic here"},
+				})
+			}
+		}
+
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{Version: _sarifVersion, Schema: _sarifSchema, Runs: []sarifRun{run}}); err != nil {
+		return fmt.Errorf("encode SARIF log: %w", err)
+	}
+	return nil
+}
+
+// sarifPhysicalLocationOf converts the position to a SARIF physical location, or returns nil if
+// the position is invalid.
+func sarifPhysicalLocationOf(position token.Position, wd string) *sarifPhysicalLocation {
+	if !position.IsValid() {
+		return nil
+	}
+
+	artifact := sarifArtifactLocation{URI: filepath.ToSlash(position.Filename)}
+	if filepath.IsAbs(position.Filename) {
+		if rel, err := filepath.Rel(wd, position.Filename); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			artifact = sarifArtifactLocation{URI: filepath.ToSlash(rel), URIBaseID: _sarifSrcRoot}
+		} else {
+			artifact.URI = (&url.URL{Scheme: "file", Path: filepath.ToSlash(position.Filename)}).String()
+		}
+	}
+
+	return &sarifPhysicalLocation{
+		ArtifactLocation: artifact,
+		// Column could be 0 for positions in files imported from archives, where only the line
+		// information is accurate.
+		Region: &sarifRegion{StartLine: position.Line, StartColumn: position.Column},
+	}
+}
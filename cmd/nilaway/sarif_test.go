@@ -0,0 +1,91 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/nilaway/diagnostic"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	t.Parallel()
+
+	deref := token.Position{Filename: "/work/pkg/foo.go", Line: 10, Column: 5}
+	diagnostics := []reportedDiagnostic{
+		{
+			Position: deref,
+			Message:  "Potential nil panic detected.\n",
+			Conflict: &diagnostic.Conflict{
+				Position: deref,
+				NilPath: []diagnostic.FlowStep{{
+					ProducerPosition: token.Position{Filename: "/work/pkg/foo.go", Line: 3, Column: 9},
+					ProducerRepr:     "literal `nil`",
+					ConsumerPosition: token.Position{Filename: "/work/pkg/foo.go", Line: 3, Column: 2},
+					ConsumerRepr:     "returned from `bar()`",
+				}},
+				NonNilPath: []diagnostic.FlowStep{{
+					ProducerRepr:     "result 0 of `bar()`",
+					ConsumerPosition: deref,
+					ConsumerRepr:     "dereferenced",
+				}},
+				SimilarPositions: []token.Position{{Filename: "/other/baz.go", Line: 7, Column: 1}},
+			},
+		},
+		{
+			Position: token.Position{Filename: "/work/pkg/foo.go", Line: 1, Column: 1},
+			Message:  "INTERNAL ERROR: foo",
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeSARIF(&buf, diagnostics, "/work"))
+
+	var log sarifLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+	require.Equal(t, "2.1.0", log.Version)
+	require.Len(t, log.Runs, 1)
+	require.Len(t, log.Runs[0].Results, 2)
+
+	result := log.Runs[0].Results[0]
+	require.Equal(t, "Potential nil panic detected.", result.Message.Text)
+	require.Len(t, result.Locations, 1)
+	require.Equal(t, sarifArtifactLocation{URI: "pkg/foo.go", URIBaseID: _sarifSrcRoot}, result.Locations[0].PhysicalLocation.ArtifactLocation)
+	require.Equal(t, &sarifRegion{StartLine: 10, StartColumn: 5}, result.Locations[0].PhysicalLocation.Region)
+
+	// The nil flow should be a single thread flow with all steps in order.
+	require.Len(t, result.CodeFlows, 1)
+	require.Len(t, result.CodeFlows[0].ThreadFlows, 1)
+	steps := result.CodeFlows[0].ThreadFlows[0].Locations
+	require.Len(t, steps, 2)
+	require.Equal(t, "literal `nil` returned from `bar()`", steps[0].Location.Message.Text)
+	require.Equal(t, 3, steps[0].Location.PhysicalLocation.Region.StartLine)
+	require.Equal(t, "result 0 of `bar()` dereferenced", steps[1].Location.Message.Text)
+	require.Equal(t, 10, steps[1].Location.PhysicalLocation.Region.StartLine)
+
+	// Similar conflicts should be related locations, where the files outside the working directory
+	// are referred to by absolute URIs.
+	require.Len(t, result.RelatedLocations, 1)
+	require.Equal(t, 1, result.RelatedLocations[0].ID)
+	require.Equal(t, sarifArtifactLocation{URI: "file:///other/baz.go"}, result.RelatedLocations[0].PhysicalLocation.ArtifactLocation)
+
+	// Diagnostics not generated from conflicts should not have flows.
+	require.Empty(t, log.Runs[0].Results[1].CodeFlows)
+	require.Empty(t, log.Runs[0].Results[1].RelatedLocations)
+}
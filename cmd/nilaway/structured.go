@@ -0,0 +1,387 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"go.uber.org/nilaway/accumulation"
+	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/diagnostic"
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	// _formatText is the default output format, where the errors are printed as plain text by
+	// the singlechecker (or by the parent process if a baseline is involved).
+	_formatText = "text"
+	// _formatSARIF is the output format for SARIF 2.1.0 logs.
+	_formatSARIF = "sarif"
+	// _formatJSON is the output format for JSON, where each error carries its structured nil flow.
+	_formatJSON = "json"
+)
+
+// _structuredOutputEnv is the environment variable that instructs the NilAway process to report
+// the structured diagnostics (encoded in the diagnostic messages) instead of the plain ones. It is
+// set for the child process spawned for structured output formats.
+const _structuredOutputEnv = "NILAWAY_STRUCTURED_OUTPUT"
+
+// reportedDiagnostic is the structured form of a diagnostic reported by NilAway. It is encoded
+// as the diagnostic message by the child process and decoded back by the parent process.
+type reportedDiagnostic struct {
+	// Position is the position of the diagnostic.
+	Position token.Position
+	// Message is the plain (i.e., not pretty-printed) message of the diagnostic.
+	Message string
+	// TextMessage is the message of the diagnostic for the text format, which is pretty-printed
+	// if configured.
+	TextMessage string
+	// Function is the full name of the function enclosing the diagnostic, or the package path if
+	// the diagnostic is not within a function.
+	Function string
+	// Conflict is the structured conflict the diagnostic is generated from, or nil if the
+	// diagnostic is not generated from a conflict (e.g., for internal errors).
+	Conflict *diagnostic.Conflict
+}
+
+// reportStructured reports the diagnostics from the accumulation analyzer with their structured
+// forms encoded as the messages, for the parent process to decode.
+func reportStructured(pass *analysis.Pass) error {
+	conf := pass.ResultOf[config.Analyzer].(*config.Config)
+	for _, d := range pass.ResultOf[accumulation.Analyzer].([]diagnostic.Diagnostic) {
+		textMessage := d.Message
+		if conf.PrettyPrint {
+			textMessage = util.PrettyPrintErrorMessage(textMessage)
+		}
+		message, err := json.Marshal(reportedDiagnostic{
+			Position:    pass.Fset.Position(d.Pos),
+			Message:     d.Message,
+			TextMessage: textMessage,
+			Function:    enclosingFunction(pass, d.Pos),
+			Conflict:    d.Conflict,
+		})
+		if err != nil {
+			return fmt.Errorf("encode structured diagnostic: %w", err)
+		}
+		d.Diagnostic.Message = string(message)
+		pass.Report(d.Diagnostic)
+	}
+	return nil
+}
+
+// enclosingFunction returns the full name of the function declaration enclosing the position
+// (see [types.Func.FullName]), or the package path if the position is not within a function.
+func enclosingFunction(pass *analysis.Pass, pos token.Pos) string {
+	for _, file := range pass.Files {
+		if pos < file.FileStart || pos > file.FileEnd {
+			continue
+		}
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if !ok || pos < funcDecl.Pos() || pos > funcDecl.End() {
+				continue
+			}
+			if fn, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+				return fn.FullName()
+			}
+		}
+	}
+	return pass.Pkg.Path()
+}
+
+// structuredOptions is the set of driver flags for running NilAway in a child process.
+type structuredOptions struct {
+	// format is the output format of the errors.
+	format string
+	// output is the file to write the errors to, or empty for the default destination of the
+	// format (stderr for text, stdout otherwise).
+	output string
+	// baseline is the baseline file of the known errors to suppress, or empty if not specified.
+	baseline string
+	// writeBaseline is the baseline file to write all errors to, or empty if not specified.
+	writeBaseline string
+	// wd is the current working directory.
+	wd string
+}
+
+// runStructured runs NilAway in a child process to collect the structured diagnostics,
+// post-processes them (e.g., writes or applies the baseline), writes them to the output in the
+// given format, and returns the exit code.
+//
+// The singlechecker exits the process right after printing the diagnostics, so there is no
+// chance to aggregate the diagnostics from all packages in the same process. Instead, we run the
+// singlechecker in a child process in JSON mode, and post-process its output here.
+func runStructured(opts structuredOptions) int {
+	var write func(io.Writer, []reportedDiagnostic, string) error
+	switch opts.format {
+	case _formatText:
+		write = writeText
+	case _formatSARIF:
+		write = writeSARIF
+	case _formatJSON:
+		write = writeJSON
+	default:
+		fmt.Fprintf(os.Stderr, "nilaway: unknown output format %q\n", opts.format)
+		return 1
+	}
+	if opts.baseline != "" && opts.writeBaseline != "" {
+		fmt.Fprintf(os.Stderr, "nilaway: -baseline and -write-baseline cannot be used together\n")
+		return 1
+	}
+	// Read the baseline before the (possibly long) analysis to fail fast on invalid baselines.
+	var known *baseline
+	if opts.baseline != "" {
+		b, err := readBaseline(opts.baseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "nilaway: %v\n", err)
+			return 1
+		}
+		known = b
+	}
+
+	diagnostics, analysisFailed, err := collectStructured()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nilaway: %v\n", err)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		return 1
+	}
+
+	if opts.writeBaseline != "" {
+		if err := writeBaseline(opts.writeBaseline, diagnostics); err != nil {
+			fmt.Fprintf(os.Stderr, "nilaway: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "nilaway: wrote %d error(s) to baseline %q\n", len(diagnostics), opts.writeBaseline)
+		if analysisFailed {
+			return 1
+		}
+		return 0
+	}
+
+	if known != nil {
+		var disappeared []baselineEntry
+		diagnostics, disappeared = known.filter(diagnostics)
+		if len(disappeared) > 0 {
+			fmt.Fprintf(os.Stderr, "nilaway: %d baseline entries no longer match any error, consider regenerating baseline %q:\n", len(disappeared), opts.baseline)
+			for _, entry := range disappeared {
+				fmt.Fprintf(os.Stderr, "\t%s (x%d) in %s: %s -> %s\n", entry.Fingerprint, entry.Count, entry.Function, entry.Source, entry.Dereference)
+			}
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if opts.format == _formatText {
+		// Follow the singlechecker to print plain text errors to stderr.
+		w = os.Stderr
+	}
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "nilaway: create output file: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := write(w, diagnostics, opts.wd); err != nil {
+		fmt.Fprintf(os.Stderr, "nilaway: %v\n", err)
+		return 1
+	}
+
+	// Follow the exit codes of the singlechecker in plain text mode: 1 for analysis errors, and 3
+	// for diagnostics.
+	switch {
+	case analysisFailed:
+		return 1
+	case len(diagnostics) > 0:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// writeText writes the reported diagnostics in the same plain text form as the singlechecker,
+// i.e., the position followed by the (possibly pretty-printed) message. The working directory is
+// unused since the positions are written as is.
+func writeText(w io.Writer, diagnostics []reportedDiagnostic, _ string) error {
+	for _, d := range diagnostics {
+		if _, err := fmt.Fprintf(w, "%s: %s\n", d.Position, d.TextMessage); err != nil {
+			return fmt.Errorf("write text: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeJSON writes the reported diagnostics as a JSON array to the writer. Each element contains
+// the position string ("posn") and the plain message of the diagnostic, as well as the structured
+// conflict (see [diagnostic.Conflict.MarshalJSON] for its form) if the diagnostic is generated
+// from one. The working directory is unused since the positions are written as is.
+func writeJSON(w io.Writer, diagnostics []reportedDiagnostic, _ string) error {
+	type jsonDiagnostic struct {
+		Posn     string               `json:"posn"`
+		Message  string               `json:"message"`
+		Conflict *diagnostic.Conflict `json:"conflict,omitempty"`
+	}
+
+	out := make([]jsonDiagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		out = append(out, jsonDiagnostic{
+			Posn:     d.Position.String(),
+			Message:  strings.TrimSpace(d.Message),
+			Conflict: d.Conflict,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return fmt.Errorf("encode JSON: %w", err)
+	}
+	return nil
+}
+
+// collectStructured runs NilAway in a child process with the same arguments in JSON mode, and
+// returns the decoded diagnostics sorted by their positions. It also returns true if the analysis
+// failed on any package, in which case the errors are printed to stderr.
+func collectStructured() ([]reportedDiagnostic, bool, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, false, fmt.Errorf("find executable: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	cmd := exec.Command(executable, append([]string{"-json"}, os.Args[1:]...)...)
+	cmd.Env = append(os.Environ(), _structuredOutputEnv+"=1")
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, false, fmt.Errorf("run NilAway: %w", err)
+	}
+
+	// Package ID -> analyzer name -> either a list of diagnostics or an error.
+	var tree map[string]map[string]json.RawMessage
+	if err := json.NewDecoder(&stdout).Decode(&tree); err != nil {
+		return nil, false, fmt.Errorf("decode JSON output: %w", err)
+	}
+
+	pkgs := make([]string, 0, len(tree))
+	for pkg := range tree {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+
+	var (
+		diagnostics    []reportedDiagnostic
+		analysisFailed bool
+	)
+	// The same diagnostic can be reported multiple times for source files that belong to multiple
+	// packages, such as foo and foo.test.
+	type key struct {
+		position token.Position
+		message  string
+	}
+	seen := make(map[key]bool)
+	for _, pkg := range pkgs {
+		for name, raw := range tree[pkg] {
+			var jsonErr struct {
+				Err string `json:"error"`
+			}
+			if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+				if err := json.Unmarshal(raw, &jsonErr); err != nil {
+					return nil, false, fmt.Errorf("decode error of %q on package %q: %w", name, pkg, err)
+				}
+				fmt.Fprintf(os.Stderr, "%s: %s: %s\n", pkg, name, jsonErr.Err)
+				analysisFailed = true
+				continue
+			}
+			if name != Analyzer.Name {
+				continue
+			}
+
+			var jsonDiagnostics []struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(raw, &jsonDiagnostics); err != nil {
+				return nil, false, fmt.Errorf("decode diagnostics on package %q: %w", pkg, err)
+			}
+			for _, jd := range jsonDiagnostics {
+				var d reportedDiagnostic
+				if err := json.Unmarshal([]byte(jd.Message), &d); err != nil {
+					return nil, false, fmt.Errorf("decode structured diagnostic %q: %w", jd.Message, err)
+				}
+				k := key{position: d.Position, message: d.Message}
+				if seen[k] {
+					continue
+				}
+				seen[k] = true
+				diagnostics = append(diagnostics, d)
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		pi, pj := diagnostics[i].Position, diagnostics[j].Position
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+		if pi.Column != pj.Column {
+			return pi.Column < pj.Column
+		}
+		return diagnostics[i].Message < diagnostics[j].Message
+	})
+	return diagnostics, analysisFailed, nil
+}
+
+// lookupFlag returns the value of the flag with the given name in the command-line arguments,
+// and false if the flag is not present. This is needed for the driver flags that must be known
+// before the singlechecker parses the flags. Note that it recognizes "-name=value",
+// "--name=value", "-name value" and "--name value" forms only.
+func lookupFlag(args []string, name string) (string, bool) {
+	value, found := "", false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		for _, prefix := range [...]string{"-", "--"} {
+			if v, ok := strings.CutPrefix(arg, prefix+name+"="); ok {
+				value, found = v, true
+			} else if arg == prefix+name && i+1 < len(args) {
+				i++
+				value, found = args[i], true
+			}
+		}
+	}
+	return value, found
+}
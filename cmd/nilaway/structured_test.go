@@ -0,0 +1,45 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupFlag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args  []string
+		value string
+		found bool
+	}{
+		{args: []string{"./..."}},
+		{args: []string{"-format=sarif", "./..."}, value: "sarif", found: true},
+		{args: []string{"--format=sarif", "./..."}, value: "sarif", found: true},
+		{args: []string{"-include-pkgs", "foo", "-format", "sarif", "./..."}, value: "sarif", found: true},
+		{args: []string{"--format", "sarif", "./..."}, value: "sarif", found: true},
+		{args: []string{"-formatted", "./..."}},
+		{args: []string{"-format"}},
+		{args: []string{"--", "-format=sarif"}},
+	}
+	for _, tt := range tests {
+		value, found := lookupFlag(tt.args, "format")
+		require.Equal(t, tt.found, found, "args: %v", tt.args)
+		require.Equal(t, tt.value, value, "args: %v", tt.args)
+	}
+}
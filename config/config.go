@@ -19,8 +19,11 @@ import (
 	"flag"
 	"go/ast"
 	"go/types"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"time"
 
 	"go.uber.org/nilaway/util/asthelper"
 	"golang.org/x/tools/go/analysis"
@@ -34,6 +37,18 @@ type Config struct {
 	ExperimentalStructInitEnable bool
 	// ExperimentalAnonymousFuncEnable indicates whether experimental anonymous function support is enabled.
 	ExperimentalAnonymousFuncEnable bool
+	// ExperimentalContractInferenceEnable indicates whether experimental inference of function
+	// contracts from the function bodies is enabled.
+	ExperimentalContractInferenceEnable bool
+	// ReportUnusedIgnores indicates whether `//nilaway:ignore` directives that do not suppress any
+	// diagnostic should be reported.
+	ReportUnusedIgnores bool
+	// Annotate indicates whether to report the inferred annotations (with suggested fixes writing
+	// them into the doc comments) instead of the potential nil panics.
+	Annotate bool
+	// ReportSkippedFuncs indicates whether the functions skipped by the analysis for exceeding the
+	// size limits (see MaxFuncSizeInBytes and MaxFuncBlocks) should be reported.
+	ReportSkippedFuncs bool
 
 	// includePkgs is the list of packages to analyze.
 	includePkgs []string
@@ -44,6 +59,140 @@ type Config struct {
 	// string, will cause the file to be excluded from analysis. Examples include "@generated" and
 	// "Code generated by".
 	excludeFileDocStrings []string
+	// defaultNilableTypes is the list of fully-qualified type names (e.g., "database/sql.Rows" or
+	// "*database/sql.Rows") that are nilable by default.
+	defaultNilableTypes []string
+	// defaultNonnilTypes is the list of fully-qualified type names that are always treated as
+	// nonnil by default, which takes precedence over the other default nilability rules (e.g., for
+	// slices, maps and the defaultNilableTypes list).
+	defaultNonnilTypes []string
+	// trustedFuncs is the list of user-declared trusted functions.
+	trustedFuncs []TrustedFunc
+	// annotationStubDirs is the list of directories containing the annotation stub files.
+	annotationStubDirs []string
+	// explainSite is the query naming the annotation site to explain (see annotation.LookupSite),
+	// or empty if not in explain mode.
+	explainSite string
+	// implicationGraphDir is the directory to dump the implication graphs of the packages to, or
+	// empty if not dumping.
+	implicationGraphDir string
+	// implicationGraphFunc is the function (e.g., "go.uber.org/foo.Bar" or
+	// "go.uber.org/foo.T.Bar") to restrict the dumped implication graph to, or empty for the
+	// entire package.
+	implicationGraphFunc string
+	// maxFuncSizeInBytes is the maximum size (in bytes) of the function bodies to be analyzed, or
+	// non-positive for no limit.
+	maxFuncSizeInBytes int
+	// maxFuncBlocks is the maximum number of blocks in the CFGs of the functions to be analyzed,
+	// or non-positive for no limit.
+	maxFuncBlocks int
+	// funcTimeout is the wall-clock time budget for analyzing a single function, or non-positive
+	// for no limit.
+	funcTimeout time.Duration
+	// maxBackpropRounds is the maximum number of backpropagation rounds for analyzing a single
+	// function, or non-positive for no limit.
+	maxBackpropRounds int
+	// statsDir is the directory to write the performance statistics of the packages to, "-" for
+	// printing them to stderr, or empty if not collecting the statistics.
+	statsDir string
+}
+
+// TrustedFuncs returns the list of trusted functions declared by the users.
+func (c *Config) TrustedFuncs() []TrustedFunc {
+	return c.trustedFuncs
+}
+
+// IsDefaultNilableType returns true iff the type is declared as default nilable by the users.
+func (c *Config) IsDefaultNilableType(t types.Type) bool {
+	return typeNameIn(t, c.defaultNilableTypes)
+}
+
+// IsDefaultNonnilType returns true iff the type is declared as default nonnil by the users.
+func (c *Config) IsDefaultNonnilType(t types.Type) bool {
+	return typeNameIn(t, c.defaultNonnilTypes)
+}
+
+// AnnotationStubDirs returns the list of directories containing the annotation stub files.
+func (c *Config) AnnotationStubDirs() []string {
+	return c.annotationStubDirs
+}
+
+// ExplainSite returns the query naming the annotation site to explain, or empty if NilAway is
+// not in explain mode.
+func (c *Config) ExplainSite() string {
+	return c.explainSite
+}
+
+// ImplicationGraphDir returns the directory to dump the implication graphs of the packages to, or
+// empty if the graphs should not be dumped.
+func (c *Config) ImplicationGraphDir() string {
+	return c.implicationGraphDir
+}
+
+// ImplicationGraphFunc returns the function to restrict the dumped implication graph to, or empty
+// if the graphs of the entire packages should be dumped.
+func (c *Config) ImplicationGraphFunc() string {
+	return c.implicationGraphFunc
+}
+
+// MaxFuncSizeInBytes returns the maximum size (in bytes) of the function bodies to be analyzed, or
+// a non-positive value if there is no limit.
+func (c *Config) MaxFuncSizeInBytes() int {
+	return c.maxFuncSizeInBytes
+}
+
+// MaxFuncBlocks returns the maximum number of blocks in the CFGs of the functions to be analyzed,
+// or a non-positive value if there is no limit.
+func (c *Config) MaxFuncBlocks() int {
+	return c.maxFuncBlocks
+}
+
+// FuncTimeout returns the wall-clock time budget for analyzing a single function, or a
+// non-positive value if there is no limit.
+func (c *Config) FuncTimeout() time.Duration {
+	return c.funcTimeout
+}
+
+// MaxBackpropRounds returns the maximum number of backpropagation rounds for analyzing a single
+// function, or a non-positive value if there is no limit.
+func (c *Config) MaxBackpropRounds() int {
+	return c.maxBackpropRounds
+}
+
+// StatsDir returns the directory to write the performance statistics of the packages to, "-" if
+// they should be printed to stderr, or empty if they should not be collected.
+func (c *Config) StatsDir() string {
+	return c.statsDir
+}
+
+// typeNameIn returns true iff the fully-qualified name of the named type (or pointer to a named
+// type) is in the list of names. The type arguments of generic types are ignored, i.e., the name
+// of `Option[int]` in package "go.uber.org/foo" is "go.uber.org/foo.Option".
+func typeNameIn(t types.Type, names []string) bool {
+	if len(names) == 0 {
+		return false
+	}
+
+	prefix := ""
+	if ptr, ok := t.(*types.Pointer); ok {
+		prefix, t = "*", ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Origin().Obj()
+	name := prefix + obj.Name()
+	if obj.Pkg() != nil {
+		name = prefix + obj.Pkg().Path() + "." + obj.Name()
+	}
+
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
 }
 
 // IsPkgInScope returns true iff the passed package is in scope for analysis, i.e., it is in the
@@ -93,7 +242,7 @@ func (c *Config) IsFileInScope(file *ast.File) bool {
 	return true
 }
 
-const _doc = `nilaway_config analyzer is responsible to take configurations (flags) for NilAway execution.
+const _doc = `nilaway_config analyzer is responsible to take configurations (flags and config file) for NilAway execution.
 It does not run any analysis and is only meant to be used as a dependency for the sub-analyzers of 
 NilAway to share the same configurations. 
 `
@@ -131,8 +280,80 @@ const (
 	ExperimentalStructInitEnableFlag = "experimental-struct-init"
 	// ExperimentalAnonymousFunctionFlag is the flag name for the experimental anonymous function support.
 	ExperimentalAnonymousFunctionFlag = "experimental-anonymous-function"
+	// ExperimentalContractInferenceFlag is the flag name for the experimental inference of function
+	// contracts.
+	ExperimentalContractInferenceFlag = "experimental-contract-inference"
+	// ReportUnusedIgnoresFlag is the flag name for reporting unused `//nilaway:ignore` directives.
+	ReportUnusedIgnoresFlag = "report-unused-ignores"
+	// ConfigFlag is the flag name for the path to the config file.
+	ConfigFlag = "config"
+	// DefaultNilableTypesFlag is the flag name for the types that are nilable by default.
+	DefaultNilableTypesFlag = "default-nilable-types"
+	// DefaultNonnilTypesFlag is the flag name for the types that are always nonnil by default.
+	DefaultNonnilTypesFlag = "default-nonnil-types"
+	// AnnotationStubsFlag is the flag name for the directories containing the annotation stub files.
+	AnnotationStubsFlag = "annotation-stubs"
+	// AnnotateFlag is the flag name for reporting the inferred annotations instead of errors.
+	AnnotateFlag = "annotate"
+	// ExplainFlag is the flag name for the annotation site to explain instead of reporting errors.
+	ExplainFlag = "explain"
+	// ImplicationGraphDirFlag is the flag name for the directory to dump the implication graphs to.
+	ImplicationGraphDirFlag = "dump-implication-graph"
+	// ImplicationGraphFuncFlag is the flag name for the function to restrict the dumped
+	// implication graph to.
+	ImplicationGraphFuncFlag = "dump-implication-graph-func"
+	// MaxFuncSizeFlag is the flag name for the maximum size (in bytes) of the functions to analyze.
+	MaxFuncSizeFlag = "max-func-size"
+	// MaxFuncBlocksFlag is the flag name for the maximum number of CFG blocks of the functions to
+	// analyze.
+	MaxFuncBlocksFlag = "max-func-blocks"
+	// ReportSkippedFuncsFlag is the flag name for reporting the functions skipped for exceeding
+	// the size limits.
+	ReportSkippedFuncsFlag = "report-skipped-funcs"
+	// FuncTimeoutFlag is the flag name for the time budget of analyzing a single function.
+	FuncTimeoutFlag = "func-timeout"
+	// MaxBackpropRoundsFlag is the flag name for the maximum number of backpropagation rounds of
+	// analyzing a single function.
+	MaxBackpropRoundsFlag = "max-backprop-rounds"
+	// StatsFlag is the flag name for the destination of the performance statistics.
+	StatsFlag = "stats"
 )
 
+// DefaultMaxFuncSizeInBytes is the default limit on the size of the function bodies to analyze,
+// which prevents the expensive assertion analysis from being run on overly-sized functions.
+const DefaultMaxFuncSizeInBytes = 10000
+
+// trackedValue wraps a flag value and records whether it has been explicitly set, such that the
+// flags explicitly set by the users can take precedence over the config file. Note that the value
+// (instead of the flag set) keeps the record since the flags may be lifted to other flag sets
+// sharing the same values (e.g., in the standalone checker).
+type trackedValue struct {
+	flag.Getter
+	isSet bool
+}
+
+func (v *trackedValue) Set(s string) error {
+	v.isSet = true
+	return v.Getter.Set(s)
+}
+
+// String forwards the string representation of the wrapped value. Note that the flag package may
+// call it on a zero trackedValue without a wrapped value to find out the zero value of the flag
+// when printing the defaults (e.g., for "-help").
+func (v *trackedValue) String() string {
+	if v.Getter == nil {
+		return ""
+	}
+	return v.Getter.String()
+}
+
+// IsBoolFlag forwards the boolean flag indicator of the wrapped value, such that boolean flags can
+// still be specified without values (e.g., "-flag" instead of "-flag=true").
+func (v *trackedValue) IsBoolFlag() bool {
+	b, ok := v.Getter.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
 // newFlagSet returns a flag set to be used in the nilaway config analyzer.
 func newFlagSet() flag.FlagSet {
 	fs := flag.NewFlagSet("nilaway_config", flag.ExitOnError)
@@ -145,10 +366,33 @@ func newFlagSet() flag.FlagSet {
 	_ = fs.String(ExcludeFileDocStringsFlag, "", "Comma-separated list of docstrings to exclude from analysis")
 	_ = fs.Bool(ExperimentalStructInitEnableFlag, false, "Whether to enable experimental struct initialization support")
 	_ = fs.Bool(ExperimentalAnonymousFunctionFlag, false, "Whether to enable experimental anonymous function support")
+	_ = fs.Bool(ExperimentalContractInferenceFlag, false, "Whether to enable experimental inference of `contract(nonnil -> nonnil)` function contracts from the function bodies")
+	_ = fs.Bool(ReportUnusedIgnoresFlag, false, "Whether to report //nilaway:ignore directives that do not suppress any error")
+	_ = fs.String(DefaultNilableTypesFlag, "", "Comma-separated list of fully-qualified type names (e.g., \"database/sql.Rows\" or \"*database/sql.Rows\") that are nilable by default")
+	_ = fs.String(DefaultNonnilTypesFlag, "", "Comma-separated list of fully-qualified type names that are always nonnil by default, which takes precedence over default-nilable-types")
+	_ = fs.String(AnnotationStubsFlag, "", "Comma-separated list of directories containing the annotation stub files (<dir>/<package path>.yaml) for the packages that cannot be annotated in source")
+	_ = fs.Bool(AnnotateFlag, false, "Whether to report the inferred annotations as suggested fixes of the doc comments instead of reporting errors (used by \"nilaway annotate\")")
+	_ = fs.String(ExplainFlag, "", "Explain why the annotation site is inferred nilable or nonnil instead of reporting errors, where the site is named as \"<pkg path>.<func>:<param/result name or index, e.g., param 0>\", \"<pkg path>.<type>.<method>:<...>\", \"<pkg path>.<type>.<field>\" or \"<pkg path>.<global var>\"")
+	_ = fs.String(ImplicationGraphDirFlag, "", "Directory to dump the implication graphs used by inference to (as <dir>/<package path>.dot and .json) for debugging")
+	_ = fs.String(ImplicationGraphFuncFlag, "", "Restrict the dumped implication graph to the sites reachable from the function, named as \"<pkg path>.<func>\" or \"<pkg path>.<type>.<method>\"")
+	_ = fs.Int(MaxFuncSizeFlag, DefaultMaxFuncSizeInBytes, "Maximum size (in bytes) of the function bodies to analyze, larger functions are skipped (0 for no limit)")
+	_ = fs.Int(MaxFuncBlocksFlag, 0, "Maximum number of blocks in the control flow graphs of the functions to analyze, larger functions are skipped (0 for no limit)")
+	_ = fs.Bool(ReportSkippedFuncsFlag, false, "Whether to report the functions skipped for exceeding max-func-size or max-func-blocks")
+	_ = fs.Duration(FuncTimeoutFlag, 0, "Wall-clock time budget (e.g., \"10s\") for analyzing a single function, functions exceeding it are abandoned and reported as incompletely analyzed (0 for no limit)")
+	_ = fs.Int(MaxBackpropRoundsFlag, 0, "Maximum number of backpropagation rounds for analyzing a single function, functions exceeding it are abandoned and reported as incompletely analyzed (0 for no limit)")
+	_ = fs.String(StatsFlag, "", "Directory to write the performance statistics (time spent in the sub-analyzers, per-function backpropagation rounds and assertion tree sizes, etc.) of the packages to as JSON (as <dir>/<package path>.json), or \"-\" to print them to stderr")
+	_ = fs.String(ConfigFlag, "", "Path to the config file (YAML or JSON), default is "+FileName+" at the root of the module in the working directory if it exists. Flags take precedence over the config file")
 
+	fs.VisitAll(func(f *flag.Flag) { f.Value = &trackedValue{Getter: f.Value.(flag.Getter)} })
 	return *fs
 }
 
+// isFlagSet returns true if the flag has been explicitly set by the user.
+func isFlagSet(pass *analysis.Pass, name string) bool {
+	v, ok := pass.Analyzer.Flags.Lookup(name).Value.(*trackedValue)
+	return ok && v.isSet
+}
+
 func run(pass *analysis.Pass) (any, error) {
 	// Set up default values for the config.
 	conf := &Config{
@@ -156,18 +400,70 @@ func run(pass *analysis.Pass) (any, error) {
 		// If the user does not provide an include list, we give an empty package prefix to catch
 		// all packages.
 		includePkgs: []string{""},
+		// The built-in default nilable named types can be extended by the users.
+		defaultNilableTypes: DefaultNilableNamedTypes[:],
+		maxFuncSizeInBytes:  DefaultMaxFuncSizeInBytes,
 	}
 
-	// Override default values if the user provides flags.
-	if prettyPrint, ok := pass.Analyzer.Flags.Lookup(PrettyPrintFlag).Value.(flag.Getter).Get().(bool); ok {
+	// Override default values if the user provides a config file, or if there is one at the root
+	// of the module in the working directory. Note that we do not look for the module root of the
+	// package itself: the dependencies (e.g., stdlib) are analyzed as well and must be configured
+	// by the same file, otherwise they would be analyzed with the default (i.e., include all)
+	// config and export conflicting facts.
+	path, _ := pass.Analyzer.Flags.Lookup(ConfigFlag).Value.(flag.Getter).Get().(string)
+	explicit := path != ""
+	if !explicit {
+		if cwd, err := os.Getwd(); err == nil {
+			if root := findModuleRoot(cwd); root != "" {
+				path = filepath.Join(root, FileName)
+			}
+		}
+	}
+	if path != "" {
+		fileConf, err := loadFileConfig(path, explicit)
+		if err != nil {
+			return nil, err
+		}
+		if fileConf != nil {
+			fileConf.apply(conf, pass.Pkg.Path())
+		}
+	}
+
+	// Override values if the user explicitly provides flags, which take precedence over the config
+	// file.
+	if prettyPrint, ok := pass.Analyzer.Flags.Lookup(PrettyPrintFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, PrettyPrintFlag) {
 		conf.PrettyPrint = prettyPrint
 	}
-	if enableStructInit, ok := pass.Analyzer.Flags.Lookup(ExperimentalStructInitEnableFlag).Value.(flag.Getter).Get().(bool); ok {
+	if enableStructInit, ok := pass.Analyzer.Flags.Lookup(ExperimentalStructInitEnableFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, ExperimentalStructInitEnableFlag) {
 		conf.ExperimentalStructInitEnable = enableStructInit
 	}
-	if enableAnonymousFunc, ok := pass.Analyzer.Flags.Lookup(ExperimentalAnonymousFunctionFlag).Value.(flag.Getter).Get().(bool); ok {
+	if enableAnonymousFunc, ok := pass.Analyzer.Flags.Lookup(ExperimentalAnonymousFunctionFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, ExperimentalAnonymousFunctionFlag) {
 		conf.ExperimentalAnonymousFuncEnable = enableAnonymousFunc
 	}
+	if enableContractInference, ok := pass.Analyzer.Flags.Lookup(ExperimentalContractInferenceFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, ExperimentalContractInferenceFlag) {
+		conf.ExperimentalContractInferenceEnable = enableContractInference
+	}
+	if reportUnusedIgnores, ok := pass.Analyzer.Flags.Lookup(ReportUnusedIgnoresFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, ReportUnusedIgnoresFlag) {
+		conf.ReportUnusedIgnores = reportUnusedIgnores
+	}
+	if annotate, ok := pass.Analyzer.Flags.Lookup(AnnotateFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, AnnotateFlag) {
+		conf.Annotate = annotate
+	}
+	if reportSkippedFuncs, ok := pass.Analyzer.Flags.Lookup(ReportSkippedFuncsFlag).Value.(flag.Getter).Get().(bool); ok && isFlagSet(pass, ReportSkippedFuncsFlag) {
+		conf.ReportSkippedFuncs = reportSkippedFuncs
+	}
+	if maxSize, ok := pass.Analyzer.Flags.Lookup(MaxFuncSizeFlag).Value.(flag.Getter).Get().(int); ok && isFlagSet(pass, MaxFuncSizeFlag) {
+		conf.maxFuncSizeInBytes = maxSize
+	}
+	if maxBlocks, ok := pass.Analyzer.Flags.Lookup(MaxFuncBlocksFlag).Value.(flag.Getter).Get().(int); ok && isFlagSet(pass, MaxFuncBlocksFlag) {
+		conf.maxFuncBlocks = maxBlocks
+	}
+	if timeout, ok := pass.Analyzer.Flags.Lookup(FuncTimeoutFlag).Value.(flag.Getter).Get().(time.Duration); ok && isFlagSet(pass, FuncTimeoutFlag) {
+		conf.funcTimeout = timeout
+	}
+	if maxRounds, ok := pass.Analyzer.Flags.Lookup(MaxBackpropRoundsFlag).Value.(flag.Getter).Get().(int); ok && isFlagSet(pass, MaxBackpropRoundsFlag) {
+		conf.maxBackpropRounds = maxRounds
+	}
 	if include, ok := pass.Analyzer.Flags.Lookup(IncludePkgsFlag).Value.(flag.Getter).Get().(string); ok && include != "" {
 		conf.includePkgs = strings.Split(include, ",")
 	}
@@ -177,6 +473,27 @@ func run(pass *analysis.Pass) (any, error) {
 	if docstrings, ok := pass.Analyzer.Flags.Lookup(ExcludeFileDocStringsFlag).Value.(flag.Getter).Get().(string); ok && docstrings != "" {
 		conf.excludeFileDocStrings = strings.Split(docstrings, ",")
 	}
+	if nilableTypes, ok := pass.Analyzer.Flags.Lookup(DefaultNilableTypesFlag).Value.(flag.Getter).Get().(string); ok && nilableTypes != "" {
+		conf.defaultNilableTypes = append(DefaultNilableNamedTypes[:], strings.Split(nilableTypes, ",")...)
+	}
+	if nonnilTypes, ok := pass.Analyzer.Flags.Lookup(DefaultNonnilTypesFlag).Value.(flag.Getter).Get().(string); ok && nonnilTypes != "" {
+		conf.defaultNonnilTypes = strings.Split(nonnilTypes, ",")
+	}
+	if explainSite, ok := pass.Analyzer.Flags.Lookup(ExplainFlag).Value.(flag.Getter).Get().(string); ok && explainSite != "" {
+		conf.explainSite = explainSite
+	}
+	if graphDir, ok := pass.Analyzer.Flags.Lookup(ImplicationGraphDirFlag).Value.(flag.Getter).Get().(string); ok && graphDir != "" {
+		conf.implicationGraphDir = graphDir
+	}
+	if graphFunc, ok := pass.Analyzer.Flags.Lookup(ImplicationGraphFuncFlag).Value.(flag.Getter).Get().(string); ok && graphFunc != "" {
+		conf.implicationGraphFunc = graphFunc
+	}
+	if statsDir, ok := pass.Analyzer.Flags.Lookup(StatsFlag).Value.(flag.Getter).Get().(string); ok && statsDir != "" {
+		conf.statsDir = statsDir
+	}
+	if stubDirs, ok := pass.Analyzer.Flags.Lookup(AnnotationStubsFlag).Value.(flag.Getter).Get().(string); ok && stubDirs != "" {
+		conf.annotationStubDirs = strings.Split(stubDirs, ",")
+	}
 
 	return conf, nil
 }
@@ -30,5 +30,6 @@ const NilAwayPkgPathPrefix = uberPkgPathPrefix + "/nilaway"
 // but feel free to increase.
 const DirLevelsToPrintForTriggers = 1
 
-// DefaultNilableNamedTypes is the list of type names that we interpret as default nilable.
+// DefaultNilableNamedTypes is the built-in list of type names that we interpret as default nilable,
+// which can be extended by the users via DefaultNilableTypesFlag or the config file.
 var DefaultNilableNamedTypes = [...]string{}
@@ -0,0 +1,244 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the name of the config file that is automatically discovered at the root of the
+// module in the working directory if no config file is specified via the ConfigFlag.
+const FileName = ".nilaway.yaml"
+
+// fileConfig is the content of a config file, written in YAML (or JSON, which is a subset of
+// YAML). The keys are the same as the names of the corresponding flags, for example:
+//
+//	include-pkgs: [go.uber.org/foo, go.uber.org/bar]
+//	exclude-pkgs: [go.uber.org/foo/generated]
+//	exclude-file-docstrings: ["@generated", "Code generated by"]
+//	default-nilable-types: ["*database/sql.Rows", go.uber.org/foo.Option]
+//	annotation-stubs: [nilaway/stubs]
+//	experimental-struct-init: true
+//	max-func-size: 20000
+//	func-timeout: 30s
+//	trusted-funcs:
+//	  - {pkg: go.uber.org/foo/must, name: NotNil, effect: arg-nonnil, arg: 1}
+//	overrides:
+//	  - pkgs: [go.uber.org/foo/legacy]
+//	    experimental-struct-init: false
+//
+// Fields that are absent in the file leave the defaults untouched, and the overrides are applied
+// in order to the packages matching any of their package prefixes.
+type fileConfig struct {
+	IncludePkgs           []string       `yaml:"include-pkgs"`
+	ExcludePkgs           []string       `yaml:"exclude-pkgs"`
+	ExcludeFileDocStrings []string       `yaml:"exclude-file-docstrings"`
+	DefaultNilableTypes   []string       `yaml:"default-nilable-types"`
+	DefaultNonnilTypes    []string       `yaml:"default-nonnil-types"`
+	PrettyPrint           *bool          `yaml:"pretty-print"`
+	MaxFuncSize           *int           `yaml:"max-func-size"`
+	MaxFuncBlocks         *int           `yaml:"max-func-blocks"`
+	FuncTimeout           *time.Duration `yaml:"func-timeout"`
+	MaxBackpropRounds     *int           `yaml:"max-backprop-rounds"`
+	Toggles               toggles        `yaml:",inline"`
+	// TrustedFuncs are the user-declared trusted functions (see trustedFuncEntry).
+	TrustedFuncs []trustedFuncEntry `yaml:"trusted-funcs"`
+	// AnnotationStubs are the directories containing the annotation stub files, where relative
+	// paths are relative to the directory of the config file.
+	AnnotationStubs []string `yaml:"annotation-stubs"`
+	// Overrides are the per-package overrides of the toggles and excluded file doc strings.
+	Overrides []override `yaml:"overrides"`
+
+	// trustedFuncs are the compiled TrustedFuncs.
+	trustedFuncs []TrustedFunc
+}
+
+// toggles are the boolean options that can be set globally as well as per package.
+type toggles struct {
+	ExperimentalStructInit        *bool `yaml:"experimental-struct-init"`
+	ExperimentalAnonymousFunction *bool `yaml:"experimental-anonymous-function"`
+	ExperimentalContractInference *bool `yaml:"experimental-contract-inference"`
+	ReportUnusedIgnores           *bool `yaml:"report-unused-ignores"`
+	ReportSkippedFuncs            *bool `yaml:"report-skipped-funcs"`
+}
+
+// override is a set of options applied to the packages matching any of the package prefixes.
+type override struct {
+	Pkgs                  []string `yaml:"pkgs"`
+	ExcludeFileDocStrings []string `yaml:"exclude-file-docstrings"`
+	Toggles               toggles  `yaml:",inline"`
+}
+
+// apply sets the toggles present in the file to the config.
+func (t toggles) apply(conf *Config) {
+	if t.ExperimentalStructInit != nil {
+		conf.ExperimentalStructInitEnable = *t.ExperimentalStructInit
+	}
+	if t.ExperimentalAnonymousFunction != nil {
+		conf.ExperimentalAnonymousFuncEnable = *t.ExperimentalAnonymousFunction
+	}
+	if t.ExperimentalContractInference != nil {
+		conf.ExperimentalContractInferenceEnable = *t.ExperimentalContractInference
+	}
+	if t.ReportUnusedIgnores != nil {
+		conf.ReportUnusedIgnores = *t.ReportUnusedIgnores
+	}
+	if t.ReportSkippedFuncs != nil {
+		conf.ReportSkippedFuncs = *t.ReportSkippedFuncs
+	}
+}
+
+// apply sets the options present in the file to the config for the package.
+func (f *fileConfig) apply(conf *Config, pkgPath string) {
+	if len(f.IncludePkgs) > 0 {
+		conf.includePkgs = f.IncludePkgs
+	}
+	if len(f.ExcludePkgs) > 0 {
+		conf.excludePkgs = f.ExcludePkgs
+	}
+	if len(f.ExcludeFileDocStrings) > 0 {
+		conf.excludeFileDocStrings = f.ExcludeFileDocStrings
+	}
+	if len(f.DefaultNilableTypes) > 0 {
+		conf.defaultNilableTypes = append(DefaultNilableNamedTypes[:], f.DefaultNilableTypes...)
+	}
+	if len(f.DefaultNonnilTypes) > 0 {
+		conf.defaultNonnilTypes = f.DefaultNonnilTypes
+	}
+	if f.PrettyPrint != nil {
+		conf.PrettyPrint = *f.PrettyPrint
+	}
+	if f.MaxFuncSize != nil {
+		conf.maxFuncSizeInBytes = *f.MaxFuncSize
+	}
+	if f.MaxFuncBlocks != nil {
+		conf.maxFuncBlocks = *f.MaxFuncBlocks
+	}
+	if f.FuncTimeout != nil {
+		conf.funcTimeout = *f.FuncTimeout
+	}
+	if f.MaxBackpropRounds != nil {
+		conf.maxBackpropRounds = *f.MaxBackpropRounds
+	}
+	if len(f.trustedFuncs) > 0 {
+		conf.trustedFuncs = f.trustedFuncs
+	}
+	if len(f.AnnotationStubs) > 0 {
+		conf.annotationStubDirs = f.AnnotationStubs
+	}
+	f.Toggles.apply(conf)
+
+	for _, o := range f.Overrides {
+		if !o.matches(pkgPath) {
+			continue
+		}
+		if len(o.ExcludeFileDocStrings) > 0 {
+			conf.excludeFileDocStrings = o.ExcludeFileDocStrings
+		}
+		o.Toggles.apply(conf)
+	}
+}
+
+// matches returns true if the package path has any of the package prefixes of the override.
+func (o *override) matches(pkgPath string) bool {
+	for _, prefix := range o.Pkgs {
+		if strings.HasPrefix(pkgPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFileConfig parses the content of a config file. Unknown keys are rejected to catch typos.
+func parseFileConfig(r io.Reader) (*fileConfig, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	var f fileConfig
+	if err := decoder.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	for i := range f.TrustedFuncs {
+		trustedFunc, err := f.TrustedFuncs[i].compile()
+		if err != nil {
+			return nil, err
+		}
+		f.trustedFuncs = append(f.trustedFuncs, trustedFunc)
+	}
+	return &f, nil
+}
+
+// loadedFileConfig is the result of loading a config file.
+type loadedFileConfig struct {
+	conf *fileConfig
+	err  error
+}
+
+// _fileConfigs caches the loaded config files (keyed by the file path), since the config analyzer
+// runs on every package.
+var _fileConfigs sync.Map
+
+// loadFileConfig loads the config file at the path. If the file is not explicitly specified by the
+// user, a missing file is not an error and nil is returned.
+func loadFileConfig(path string, explicit bool) (*fileConfig, error) {
+	if cached, ok := _fileConfigs.Load(path); ok {
+		loaded := cached.(loadedFileConfig)
+		return loaded.conf, loaded.err
+	}
+
+	var loaded loadedFileConfig
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		loaded.conf, loaded.err = parseFileConfig(bytes.NewReader(content))
+		if loaded.err != nil {
+			loaded.err = fmt.Errorf("parse config file %q: %w", path, loaded.err)
+			break
+		}
+		for i, dir := range loaded.conf.AnnotationStubs {
+			if !filepath.IsAbs(dir) {
+				loaded.conf.AnnotationStubs[i] = filepath.Join(filepath.Dir(path), dir)
+			}
+		}
+	case explicit || !errors.Is(err, os.ErrNotExist):
+		loaded.err = fmt.Errorf("read config file: %w", err)
+	}
+	_fileConfigs.Store(path, loaded)
+	return loaded.conf, loaded.err
+}
+
+// findModuleRoot returns the closest directory (starting from dir) that contains a go.mod file,
+// or an empty string if there is none.
+func findModuleRoot(dir string) string {
+	for {
+		if info, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,186 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileConfigApply(t *testing.T) {
+	t.Parallel()
+
+	const content = `
+include-pkgs: [go.uber.org/foo, go.uber.org/bar]
+exclude-pkgs: [go.uber.org/foo/generated]
+exclude-file-docstrings: ["@generated"]
+pretty-print: false
+experimental-struct-init: true
+max-func-size: 20000
+overrides:
+  - pkgs: [go.uber.org/foo/legacy]
+    experimental-struct-init: false
+    exclude-file-docstrings: ["@legacy"]
+  - pkgs: [go.uber.org/bar]
+    report-unused-ignores: true
+    report-skipped-funcs: true
+`
+	f, err := parseFileConfig(strings.NewReader(content))
+	require.NoError(t, err)
+
+	conf := &Config{PrettyPrint: true, includePkgs: []string{""}}
+	f.apply(conf, "go.uber.org/foo")
+	require.Equal(t, &Config{
+		ExperimentalStructInitEnable: true,
+		includePkgs:                  []string{"go.uber.org/foo", "go.uber.org/bar"},
+		excludePkgs:                  []string{"go.uber.org/foo/generated"},
+		excludeFileDocStrings:        []string{"@generated"},
+		maxFuncSizeInBytes:           20000,
+	}, conf)
+
+	conf = &Config{PrettyPrint: true, includePkgs: []string{""}}
+	f.apply(conf, "go.uber.org/foo/legacy/baz")
+	require.False(t, conf.ExperimentalStructInitEnable)
+	require.False(t, conf.ReportUnusedIgnores)
+	require.Equal(t, []string{"@legacy"}, conf.excludeFileDocStrings)
+
+	conf = &Config{PrettyPrint: true, includePkgs: []string{""}}
+	f.apply(conf, "go.uber.org/bar")
+	require.True(t, conf.ExperimentalStructInitEnable)
+	require.True(t, conf.ReportUnusedIgnores)
+	require.True(t, conf.ReportSkippedFuncs)
+}
+
+func TestParseFileConfig(t *testing.T) {
+	t.Parallel()
+
+	// JSON is accepted as well.
+	f, err := parseFileConfig(strings.NewReader(`{"include-pkgs": ["go.uber.org/foo"], "report-unused-ignores": true}`))
+	require.NoError(t, err)
+	require.Equal(t, []string{"go.uber.org/foo"}, f.IncludePkgs)
+	require.NotNil(t, f.Toggles.ReportUnusedIgnores)
+	require.True(t, *f.Toggles.ReportUnusedIgnores)
+	require.Nil(t, f.Toggles.ExperimentalStructInit)
+
+	// Durations are written as duration strings.
+	f, err = parseFileConfig(strings.NewReader("func-timeout: 1m30s\nmax-backprop-rounds: 100"))
+	require.NoError(t, err)
+	require.NotNil(t, f.FuncTimeout)
+	require.Equal(t, 90*time.Second, *f.FuncTimeout)
+	require.NotNil(t, f.MaxBackpropRounds)
+	require.Equal(t, 100, *f.MaxBackpropRounds)
+
+	// Empty files are allowed.
+	f, err = parseFileConfig(strings.NewReader(""))
+	require.NoError(t, err)
+	require.Equal(t, &fileConfig{}, f)
+
+	// Unknown keys are rejected.
+	_, err = parseFileConfig(strings.NewReader("include-pkg: [go.uber.org/foo]"))
+	require.ErrorContains(t, err, "include-pkg")
+}
+
+func TestLoadFileConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/foo\n"), 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "pkg", "sub"), 0o755))
+	require.Equal(t, dir, findModuleRoot(filepath.Join(dir, "pkg", "sub")))
+
+	// A missing config file is only an error if explicitly specified.
+	path := filepath.Join(dir, FileName)
+	f, err := loadFileConfig(path, false /* explicit */)
+	require.NoError(t, err)
+	require.Nil(t, f)
+	_, err = loadFileConfig(filepath.Join(dir, "missing.yaml"), true /* explicit */)
+	require.Error(t, err)
+
+	path = filepath.Join(dir, "nilaway.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("include-pkgs: [example.com/foo]\n"), 0o644))
+	f, err = loadFileConfig(path, true /* explicit */)
+	require.NoError(t, err)
+	require.Equal(t, []string{"example.com/foo"}, f.IncludePkgs)
+}
+
+func TestDefaultNilabilityTypes(t *testing.T) {
+	t.Parallel()
+
+	pkg := types.NewPackage("go.uber.org/foo", "foo")
+	rows := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Rows", nil), types.NewStruct(nil, nil), nil)
+	ids := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "IDs", nil), types.NewSlice(types.Typ[types.Int]), nil)
+
+	f, err := parseFileConfig(strings.NewReader(`
+default-nilable-types: ["*go.uber.org/foo.Rows"]
+default-nonnil-types: [go.uber.org/foo.IDs]
+`))
+	require.NoError(t, err)
+	conf := &Config{}
+	f.apply(conf, "go.uber.org/foo")
+
+	require.True(t, conf.IsDefaultNilableType(types.NewPointer(rows)))
+	require.False(t, conf.IsDefaultNilableType(rows))
+	require.True(t, conf.IsDefaultNonnilType(ids))
+	require.False(t, conf.IsDefaultNonnilType(types.NewPointer(ids)))
+	require.False(t, conf.IsDefaultNonnilType(types.NewSlice(types.Typ[types.Int])))
+}
+
+func TestTrustedFuncs(t *testing.T) {
+	t.Parallel()
+
+	f, err := parseFileConfig(strings.NewReader(`
+trusted-funcs:
+  - {pkg: go.uber.org/foo/must, name: "NotNil(f)?", effect: arg-nonnil, arg: 1}
+  - {pkg: go.uber.org/foo/check, recv: Checker, name: NoErr, effect: arg-nil}
+  - {pkg: go.uber.org/foo/fatal, name: Exit, effect: no-return}
+`))
+	require.NoError(t, err)
+	conf := &Config{}
+	f.apply(conf, "go.uber.org/bar")
+	trustedFuncs := conf.TrustedFuncs()
+	require.Len(t, trustedFuncs, 3)
+
+	require.False(t, trustedFuncs[0].IsMethod)
+	require.Equal(t, TrustedFuncArgNonnil, trustedFuncs[0].Effect)
+	require.Equal(t, 1, trustedFuncs[0].Arg)
+	require.True(t, trustedFuncs[0].Enclosing.MatchString("go.uber.org/foo/must"))
+	require.False(t, trustedFuncs[0].Enclosing.MatchString("go.uber.org/foo/must/sub"))
+	require.True(t, trustedFuncs[0].Name.MatchString("NotNilf"))
+	require.False(t, trustedFuncs[0].Name.MatchString("MustNotNil"))
+
+	require.True(t, trustedFuncs[1].IsMethod)
+	require.True(t, trustedFuncs[1].Enclosing.MatchString("go.uber.org/foo/check.Checker"))
+	require.False(t, trustedFuncs[1].Enclosing.MatchString("go.uber.org/foo/check"))
+
+	require.Equal(t, TrustedFuncNoReturn, trustedFuncs[2].Effect)
+
+	// Invalid entries are rejected.
+	for _, entry := range []string{
+		`{name: NotNil, effect: arg-nonnil}`,
+		`{pkg: go.uber.org/foo, name: NotNil, effect: nonnil}`,
+		`{pkg: go.uber.org/foo, name: NotNil, effect: arg-nonnil, arg: -1}`,
+		`{pkg: go.uber.org/foo, name: "NotNil(", effect: arg-nonnil}`,
+	} {
+		_, err := parseFileConfig(strings.NewReader("trusted-funcs: [" + entry + "]"))
+		require.Error(t, err, entry)
+	}
+}
@@ -0,0 +1,123 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// TrustedFuncEffect is the effect that a user-declared trusted function has on its arguments or
+// its result.
+type TrustedFuncEffect string
+
+const (
+	// TrustedFuncArgNonnil means the function asserts that its argument is nonnil, i.e., the call
+	// does not return if the argument is nil (e.g., `must.NotNil(t, x)`).
+	TrustedFuncArgNonnil TrustedFuncEffect = "arg-nonnil"
+	// TrustedFuncArgNil means the function asserts that its argument is nil (e.g., `check.NoErr(err)`).
+	TrustedFuncArgNil TrustedFuncEffect = "arg-nil"
+	// TrustedFuncArgTrue means the function asserts that its boolean argument is true (e.g., `must.True(ok)`).
+	TrustedFuncArgTrue TrustedFuncEffect = "arg-true"
+	// TrustedFuncResultNonnil means the (first) result of the function is always nonnil (e.g., a
+	// constructor that never returns nil).
+	TrustedFuncResultNonnil TrustedFuncEffect = "result-nonnil"
+	// TrustedFuncResultNilable means the (first) result of the function is always considered nilable.
+	TrustedFuncResultNilable TrustedFuncEffect = "result-nilable"
+	// TrustedFuncNoReturn means the function never returns (e.g., a helper logging a fatal error
+	// and exiting the program), i.e., the code following the call is unreachable.
+	TrustedFuncNoReturn TrustedFuncEffect = "no-return"
+)
+
+// TrustedFunc is a function (or method) declared by the users to have a certain effect, which is
+// trusted by NilAway in the same way as the built-in trusted functions (e.g., testify assertions).
+type TrustedFunc struct {
+	// IsMethod indicates whether the trusted function is a method.
+	IsMethod bool
+	// Enclosing matches the path of the package for functions, or "<pkg path>.<type name>" for
+	// methods (e.g., "go.uber.org/foo/check.Checker").
+	Enclosing *regexp.Regexp
+	// Name matches the name of the function or method.
+	Name *regexp.Regexp
+	// Effect is the effect of the function.
+	Effect TrustedFuncEffect
+	// Arg is the index of the argument (excluding the receiver) that the effect applies to, and it
+	// is only meaningful for the effects on arguments.
+	Arg int
+}
+
+// trustedFuncEntry is an entry of the trusted functions in the config file, for example:
+//
+//	trusted-funcs:
+//	  - pkg: go.uber.org/foo/must
+//	    name: NotNil(f)?
+//	    effect: arg-nonnil
+//	    arg: 1
+//	  - pkg: go.uber.org/foo/check
+//	    recv: Checker
+//	    name: NoErr
+//	    effect: arg-nil
+//	  - pkg: go.uber.org/foo/fatal
+//	    name: Exit
+//	    effect: no-return
+//
+// The package path, receiver type name and function name are all regular expressions that must
+// match the entire names.
+type trustedFuncEntry struct {
+	Pkg    string `yaml:"pkg"`
+	Recv   string `yaml:"recv"`
+	Name   string `yaml:"name"`
+	Effect string `yaml:"effect"`
+	Arg    int    `yaml:"arg"`
+}
+
+// compile validates the entry and compiles it to a trusted function.
+func (e *trustedFuncEntry) compile() (TrustedFunc, error) {
+	if e.Pkg == "" || e.Name == "" {
+		return TrustedFunc{}, fmt.Errorf("trusted function must specify both pkg and name")
+	}
+
+	switch effect := TrustedFuncEffect(e.Effect); effect {
+	case TrustedFuncArgNonnil, TrustedFuncArgNil, TrustedFuncArgTrue:
+		if e.Arg < 0 {
+			return TrustedFunc{}, fmt.Errorf("trusted function %q: negative argument index %d", e.Name, e.Arg)
+		}
+	case TrustedFuncResultNonnil, TrustedFuncResultNilable, TrustedFuncNoReturn:
+	default:
+		return TrustedFunc{}, fmt.Errorf("trusted function %q: unknown effect %q, expecting one of %q",
+			e.Name, e.Effect, []TrustedFuncEffect{TrustedFuncArgNonnil, TrustedFuncArgNil, TrustedFuncArgTrue, TrustedFuncResultNonnil, TrustedFuncResultNilable, TrustedFuncNoReturn})
+	}
+
+	enclosing := "^(?:" + e.Pkg + ")$"
+	if e.Recv != "" {
+		enclosing = `^(?:` + e.Pkg + `)\.(?:` + e.Recv + `)$`
+	}
+	enclosingRegex, err := regexp.Compile(enclosing)
+	if err != nil {
+		return TrustedFunc{}, fmt.Errorf("trusted function %q: invalid pkg or recv pattern: %w", e.Name, err)
+	}
+	nameRegex, err := regexp.Compile("^(?:" + e.Name + ")$")
+	if err != nil {
+		return TrustedFunc{}, fmt.Errorf("trusted function %q: invalid name pattern: %w", e.Name, err)
+	}
+
+	return TrustedFunc{
+		IsMethod:  e.Recv != "",
+		Enclosing: enclosingRegex,
+		Name:      nameRegex,
+		Effect:    TrustedFuncEffect(e.Effect),
+		Arg:       e.Arg,
+	}, nil
+}
@@ -2,12 +2,14 @@ package diagnostic
 
 import (
 	"fmt"
+	"go/ast"
 	"go/token"
 	"strings"
 )
 
 type conflict struct {
 	pos              token.Pos   // stores position where the error should be reported (note that this field is used only within the current, and should NOT be exported)
+	expr             ast.Expr    // stores the consumed expression if known (i.e., for single assertion conflicts), used for suggesting fixes
 	flow             nilFlow     // stores nil flow from source to dereference point
 	similarConflicts []*conflict // stores other conflicts that are similar to this one
 }
@@ -44,6 +44,11 @@ type Engine struct {
 	// for faster lookup when converting correct upstream position back to local token.Pos for
 	// reporting purposes.
 	files map[string]fileInfo
+	// suppressions stores the `//nilaway:ignore` directives in the current package.
+	suppressions *suppressions
+	// fullNames lazily maps the truncated file names (see [util.TruncatePosition]) to the full
+	// file names in the file set, or to an empty string if the truncated name is ambiguous.
+	fullNames map[string]string
 }
 
 // NewEngine creates a new diagnostic engine.
@@ -92,30 +97,80 @@ func NewEngine(pass *analysis.Pass) *Engine {
 		return true
 	})
 
-	return &Engine{pass: pass, files: files}
+	return &Engine{pass: pass, files: files, suppressions: collectSuppressions(pass)}
 }
 
 // Diagnostics generates diagnostics from the internally-stored conflicts. The grouping parameter
 // controls whether the conflicts with the same nil flow -- the part in the complete nil flow going
 // from a nilable source point to the conflict point -- are grouped together for concise reporting.
-func (e *Engine) Diagnostics(grouping bool) []analysis.Diagnostic {
-	conflicts := e.conflicts
-	if grouping {
-		// group conflicts with the same nil path together for concise reporting
-		conflicts = groupConflicts(e.conflicts)
-	}
+// Conflicts suppressed by `//nilaway:ignore` directives on their dereference line or nil source
+// line are dropped before grouping. The diagnostics carry suggested fixes for common conflict
+// shapes (see [Engine.suggestedFixes]).
+func (e *Engine) Diagnostics(grouping bool) []Diagnostic {
+	conflicts := e.reportedConflicts(grouping)
 
 	// build diagnostics from conflicts
-	diagnostics := make([]analysis.Diagnostic, 0, len(conflicts))
+	diagnostics := make([]Diagnostic, 0, len(conflicts))
 	for _, c := range conflicts {
-		diagnostics = append(diagnostics, analysis.Diagnostic{
-			Pos:     c.pos,
-			Message: c.String(),
+		diagnostics = append(diagnostics, Diagnostic{
+			Diagnostic: analysis.Diagnostic{
+				Pos:            c.pos,
+				Message:        c.String(),
+				SuggestedFixes: e.suggestedFixes(c),
+			},
+			Conflict: e.structured(c),
 		})
 	}
 	return diagnostics
 }
 
+// Conflicts returns the structured forms of the internally-stored conflicts, which are the same
+// conflicts that [Engine.Diagnostics] generates diagnostics from (i.e., after suppression and
+// optional grouping). They can be marshalled to JSON for machine-readable outputs.
+func (e *Engine) Conflicts(grouping bool) []*Conflict {
+	conflicts := e.reportedConflicts(grouping)
+	structured := make([]*Conflict, 0, len(conflicts))
+	for _, c := range conflicts {
+		structured = append(structured, e.structured(c))
+	}
+	return structured
+}
+
+// reportedConflicts returns the conflicts to be reported, i.e., the ones not suppressed by
+// `//nilaway:ignore` directives, optionally grouped by their nil paths.
+func (e *Engine) reportedConflicts(grouping bool) []conflict {
+	conflicts := make([]conflict, 0, len(e.conflicts))
+	for _, c := range e.conflicts {
+		if !e.suppressions.suppresses(c, e.pass.Fset.Position(c.pos)) {
+			conflicts = append(conflicts, c)
+		}
+	}
+	if grouping {
+		// group conflicts with the same nil path together for concise reporting
+		conflicts = groupConflicts(conflicts)
+	}
+	return conflicts
+}
+
+// UnusedSuppressions returns diagnostics for the `//nilaway:ignore` directives in the current
+// package that do not match any conflict, such that stale directives can be cleaned up. It must be
+// called after [Engine.Diagnostics], which marks the matched directives. Note that a directive on
+// a nil source line may only match conflicts reported in downstream packages, which are invisible
+// here; such directives are reported as unused as well.
+func (e *Engine) UnusedSuppressions() []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, s := range e.suppressions.all {
+		if s.used {
+			continue
+		}
+		diagnostics = append(diagnostics, Diagnostic{Diagnostic: analysis.Diagnostic{
+			Pos:     s.pos,
+			Message: fmt.Sprintf("Unused %s directive: no potential nil panic is suppressed by it.\n", IgnoreDirective),
+		}})
+	}
+	return diagnostics
+}
+
 // AddSingleAssertionConflict adds a new single assertion conflict to the engine.
 func (e *Engine) AddSingleAssertionConflict(trigger annotation.FullTrigger) {
 	producer, consumer := trigger.Prestrings(e.pass)
@@ -124,6 +179,7 @@ func (e *Engine) AddSingleAssertionConflict(trigger annotation.FullTrigger) {
 
 	e.conflicts = append(e.conflicts, conflict{
 		pos:  trigger.Consumer.Expr.Pos(),
+		expr: trigger.Consumer.Expr,
 		flow: flow,
 	})
 }
@@ -156,8 +212,12 @@ func (e *Engine) AddOverconstraintConflict(nilReason, nonnilReason inference.Exp
 	// Different from building the nil path above, here we also want to deduce the position where the error should be reported,
 	// i.e., the point of dereference where the nil panic would occur. In NilAway's context this is the last node
 	// in the non-nil path. Therefore, we keep updating `c.pos` until we reach the end of the non-nil path.
-	var reportPosition token.Position
+	var (
+		reportPosition token.Position
+		deepestReason  inference.ExplainedBool
+	)
 	for r := nonnilReason; r != nil; r = r.DeeperReason() {
+		deepestReason = r
 		producer, consumer := r.TriggerReprs()
 		position := r.Position()
 		// Similar to above, we have two cases here:
@@ -175,12 +235,34 @@ func (e *Engine) AddOverconstraintConflict(nilReason, nonnilReason inference.Exp
 		}
 	}
 
+	// If the site is determined nonnil by an annotation outside the current package (e.g., from an
+	// annotation stub of a package that is not analyzed), the annotated declaration is not a useful
+	// place to report the error, hence we report it at the point of conflict in the current package
+	// instead.
+	pos := e.toPos(reportPosition)
+	if _, ok := deepestReason.(inference.FalseBecauseAnnotation); ok && !e.isLocal(pos) {
+		if conflictPos := e.toPos(nilReason.Position()); e.isLocal(conflictPos) {
+			pos = conflictPos
+		}
+	}
+
 	e.conflicts = append(e.conflicts, conflict{
-		pos:  e.toPos(reportPosition),
+		pos:  pos,
 		flow: flow,
 	})
 }
 
+// isLocal returns true if the position is in the files of the current package.
+func (e *Engine) isLocal(pos token.Pos) bool {
+	file := e.pass.Fset.File(pos)
+	for _, f := range e.pass.Files {
+		if e.pass.Fset.File(f.Pos()) == file {
+			return true
+		}
+	}
+	return false
+}
+
 // _fakeFileMaxLines is the maximum number of lines that the archive importer will add to a (fake)
 // file when it imports a package. See [the importer code] for more details. We use this to create
 // more fake files when necessary (see [primitivizer.sitePos]).
@@ -0,0 +1,387 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// suggestedFixes returns the fix that can be mechanically applied to resolve the conflict (or to
+// document the nilability that causes it). Currently, two kinds of fixes are suggested:
+//
+//   - inserting a nil guard `if x == nil { return ... }` (with zero-value returns) right before
+//     the statement that dereferences a side-effect-free expression `x`, e.g., a nilable local
+//     variable or an unchecked map read;
+//   - adding a `// nilable(result i)` annotation to the function in the current package that
+//     returns the nil value at the source of the nil flow.
+//
+// The two fixes contradict each other (the guard treats the nil value as expected at the
+// dereference, while the annotation only documents it and leaves the conflict in place), and
+// drivers like `-fix` apply all fixes of a diagnostic at once. Hence, at most one fix is returned,
+// where the nil guard is preferred since it actually resolves the conflict. Fixes are only
+// suggested when the edits can be made in the files of the current package.
+func (e *Engine) suggestedFixes(c conflict) []analysis.SuggestedFix {
+	if fix, ok := e.nilGuardFix(c); ok {
+		return []analysis.SuggestedFix{fix}
+	}
+	if fix, ok := e.nilableAnnotationFix(c); ok {
+		return []analysis.SuggestedFix{fix}
+	}
+	return nil
+}
+
+// nilGuardFix returns the fix that inserts a nil guard for the dereferenced expression of the
+// conflict, and false if it is not applicable.
+func (e *Engine) nilGuardFix(c conflict) (analysis.SuggestedFix, bool) {
+	file := e.fileOf(c.pos)
+	if file == nil {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// The dereferenced expression is known for single assertion conflicts, otherwise we find it
+	// by the position of the conflict.
+	expr := c.expr
+	if expr == nil {
+		path, _ := astutil.PathEnclosingInterval(file, c.pos, c.pos)
+		if expr = e.dereferencedExpr(path, c.pos); expr == nil {
+			return analysis.SuggestedFix{}, false
+		}
+	}
+	if !isSideEffectFree(expr) {
+		return analysis.SuggestedFix{}, false
+	}
+	path, exact := astutil.PathEnclosingInterval(file, expr.Pos(), expr.End())
+	if !exact || len(path) < 2 || path[0] != expr || !e.isDereferenced(expr, path[1]) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// Find the statement to insert the guard before, and the function to return from.
+	var (
+		stmt ast.Stmt
+		sig  *types.Signature
+	)
+	for i, n := range path {
+		if stmt == nil {
+			if s, ok := n.(ast.Stmt); ok && i+1 < len(path) && isListedStmt(s, path[i+1]) {
+				stmt = s
+			}
+			continue
+		}
+		if isFunc(n) {
+			sig = e.signatureOf(n)
+			break
+		}
+	}
+	if stmt == nil || sig == nil {
+		return analysis.SuggestedFix{}, false
+	}
+	// The guard is evaluated only once before the loop, so it cannot protect the loop condition
+	// or post statement.
+	if loop, ok := stmt.(*ast.ForStmt); ok && (loop.Init == nil || expr.End() > loop.Init.End()) {
+		return analysis.SuggestedFix{}, false
+	}
+	// The guard cannot refer to variables that are declared in the statement itself.
+	if e.refersToVarsIn(expr, stmt) {
+		return analysis.SuggestedFix{}, false
+	}
+
+	zeros := make([]string, 0, sig.Results().Len())
+	for i := 0; i < sig.Results().Len(); i++ {
+		zero, ok := e.zeroValue(file, sig.Results().At(i).Type())
+		if !ok {
+			return analysis.SuggestedFix{}, false
+		}
+		zeros = append(zeros, zero)
+	}
+
+	// gofmt-ed code is indented by tabs, so the column of the statement tells the indentation.
+	indent := strings.Repeat("\t", e.pass.Fset.Position(stmt.Pos()).Column-1)
+	ret := "return"
+	if len(zeros) > 0 {
+		ret += " " + strings.Join(zeros, ", ")
+	}
+	exprStr := types.ExprString(expr)
+	guard := fmt.Sprintf("if %s == nil {\n%s\t%s\n%s}\n%s", exprStr, indent, ret, indent, indent)
+
+	return analysis.SuggestedFix{
+		Message:   fmt.Sprintf("Insert nil guard for `%s`", exprStr),
+		TextEdits: []analysis.TextEdit{{Pos: stmt.Pos(), End: stmt.Pos(), NewText: []byte(guard)}},
+	}, true
+}
+
+// nilableAnnotationFix returns the fix that annotates the result of the function returning the
+// nil value at the source of the nil flow as nilable, and false if it is not applicable.
+func (e *Engine) nilableAnnotationFix(c conflict) (analysis.SuggestedFix, bool) {
+	var source node
+	switch {
+	case len(c.flow.nilPath) > 0:
+		source = c.flow.nilPath[0]
+	case len(c.flow.nonnilPath) > 0:
+		source = c.flow.nonnilPath[0]
+	default:
+		return analysis.SuggestedFix{}, false
+	}
+	file, pos := e.localPos(e.untruncate(source.producerPosition))
+	if file == nil {
+		return analysis.SuggestedFix{}, false
+	}
+
+	// Find the returned expression at the position, and the function declaration returning it.
+	path, _ := astutil.PathEnclosingInterval(file, pos, pos)
+	for i := 0; i+1 < len(path) && path[i].Pos() == pos; i++ {
+		ret, ok := path[i+1].(*ast.ReturnStmt)
+		if !ok {
+			continue
+		}
+		index := -1
+		for j, result := range ret.Results {
+			if result == path[i] {
+				index = j
+			}
+		}
+		if index < 0 {
+			return analysis.SuggestedFix{}, false
+		}
+
+		var decl *ast.FuncDecl
+		for _, n := range path[i+2:] {
+			if isFunc(n) {
+				decl, _ = n.(*ast.FuncDecl)
+				break
+			}
+		}
+		// Annotations are only supported on function declarations. We also do not attempt to
+		// merge the new annotation into existing ones.
+		if decl == nil || (decl.Doc != nil && strings.Contains(decl.Doc.Text(), "nilable(")) {
+			return analysis.SuggestedFix{}, false
+		}
+		// Make sure the results are listed one by one, i.e., not returned from a multi-valued call.
+		if sig := e.signatureOf(decl); sig == nil || sig.Results().Len() != len(ret.Results) {
+			return analysis.SuggestedFix{}, false
+		}
+
+		annotation := fmt.Sprintf("nilable(result %d)", index)
+		return analysis.SuggestedFix{
+			Message: fmt.Sprintf("Annotate `%s` with `// %s`", decl.Name.Name, annotation),
+			TextEdits: []analysis.TextEdit{{
+				Pos:     decl.Type.Func,
+				End:     decl.Type.Func,
+				NewText: []byte("// " + annotation + "\n"),
+			}},
+		}, true
+	}
+	return analysis.SuggestedFix{}, false
+}
+
+// dereferencedExpr returns the unique expression starting at the position that is dereferenced by
+// its parent in the path (see [astutil.PathEnclosingInterval]), or nil if there is none or the
+// dereferenced expression is ambiguous (e.g., `x` and `x.f` in `x.f.g`).
+func (e *Engine) dereferencedExpr(path []ast.Node, pos token.Pos) ast.Expr {
+	var found ast.Expr
+	for i := 0; i+1 < len(path) && path[i].Pos() == pos; i++ {
+		expr, ok := path[i].(ast.Expr)
+		if !ok || !e.isDereferenced(expr, path[i+1]) {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = expr
+	}
+	return found
+}
+
+// isDereferenced returns true if the nilable expression is dereferenced by its parent, i.e., the
+// parent panics if the expression is nil.
+func (e *Engine) isDereferenced(expr ast.Expr, parent ast.Node) bool {
+	tv, ok := e.pass.TypesInfo.Types[expr]
+	if !ok || !tv.IsValue() || tv.IsNil() || util.TypeBarsNilness(tv.Type) {
+		return false
+	}
+
+	switch parent := parent.(type) {
+	case *ast.StarExpr:
+		return parent.X == expr
+	case *ast.SelectorExpr:
+		_, isSelection := e.pass.TypesInfo.Selections[parent]
+		return parent.X == expr && isSelection
+	case *ast.IndexExpr:
+		// Reading a nil map is allowed.
+		return parent.X == expr && !util.TypeIsDeeplyMap(tv.Type)
+	case *ast.SliceExpr:
+		return parent.X == expr
+	}
+	return false
+}
+
+// isSideEffectFree returns true if the expression can be evaluated again in a nil guard without
+// side effects, i.e., it only consists of identifiers, literals, selectors and indices.
+func isSideEffectFree(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.Ident, *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return isSideEffectFree(expr.X)
+	case *ast.SelectorExpr:
+		return isSideEffectFree(expr.X)
+	case *ast.IndexExpr:
+		return isSideEffectFree(expr.X) && isSideEffectFree(expr.Index)
+	}
+	return false
+}
+
+// isListedStmt returns true if the statement is listed in a statement list of its parent, such
+// that another statement can be inserted before it.
+func isListedStmt(stmt ast.Stmt, parent ast.Node) bool {
+	switch stmt.(type) {
+	case *ast.CaseClause, *ast.CommClause:
+		return false
+	}
+	switch parent.(type) {
+	case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+		return true
+	}
+	return false
+}
+
+// isFunc returns true if the node is a function declaration or literal.
+func isFunc(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.FuncDecl, *ast.FuncLit:
+		return true
+	}
+	return false
+}
+
+// signatureOf returns the signature of the function declaration or literal, or nil if it is not
+// available.
+func (e *Engine) signatureOf(n ast.Node) *types.Signature {
+	var t types.Type
+	switch n := n.(type) {
+	case *ast.FuncDecl:
+		if obj := e.pass.TypesInfo.Defs[n.Name]; obj != nil {
+			t = obj.Type()
+		}
+	case *ast.FuncLit:
+		t = e.pass.TypesInfo.TypeOf(n)
+	}
+	sig, _ := t.(*types.Signature)
+	return sig
+}
+
+// refersToVarsIn returns true if the expression refers to variables declared within the node.
+func (e *Engine) refersToVarsIn(expr ast.Expr, n ast.Node) bool {
+	found := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		ident, ok := node.(*ast.Ident)
+		if !ok {
+			return !found
+		}
+		if v, ok := e.pass.TypesInfo.Uses[ident].(*types.Var); ok && !v.IsField() && v.Pos() >= n.Pos() && v.Pos() < n.End() {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// zeroValue returns the source representation of the zero value of the type, as it would be
+// written in the file, and false if it cannot be represented (e.g., unnamed structs or types from
+// packages not imported by the file).
+func (e *Engine) zeroValue(file *ast.File, t types.Type) (string, bool) {
+	ok := true
+	qualifier := func(pkg *types.Package) string {
+		if pkg == e.pass.Pkg {
+			return ""
+		}
+		for _, imp := range file.Imports {
+			if strings.Trim(imp.Path.Value, "`\"") != pkg.Path() {
+				continue
+			}
+			if imp.Name == nil {
+				return pkg.Name()
+			}
+			if imp.Name.Name != "_" && imp.Name.Name != "." {
+				return imp.Name.Name
+			}
+		}
+		ok = false
+		return pkg.Name()
+	}
+
+	if _, isTypeParam := t.(*types.TypeParam); isTypeParam {
+		return "*new(" + types.TypeString(t, qualifier) + ")", true
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false", true
+		case u.Info()&types.IsNumeric != 0:
+			return "0", true
+		case u.Info()&types.IsString != 0:
+			return `""`, true
+		case u.Kind() == types.UnsafePointer:
+			return "nil", true
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil", true
+	case *types.Struct, *types.Array:
+		if _, isNamed := t.(*types.Named); isNamed {
+			s := types.TypeString(t, qualifier) + "{}"
+			return s, ok
+		}
+	}
+	return "", false
+}
+
+// fileOf returns the file in the current package that contains the position, or nil if there is
+// none.
+func (e *Engine) fileOf(pos token.Pos) *ast.File {
+	for _, file := range e.pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// localPos converts the position (with full file name) to the file in the current package and the
+// token.Pos in it, or returns nil if the position is not in the current package.
+func (e *Engine) localPos(position token.Position) (*ast.File, token.Pos) {
+	if !position.IsValid() {
+		return nil, token.NoPos
+	}
+	for _, file := range e.pass.Files {
+		tf := e.pass.Fset.File(file.Pos())
+		if tf == nil || tf.Name() != position.Filename || position.Line > tf.LineCount() {
+			continue
+		}
+		pos := tf.LineStart(position.Line) + token.Pos(position.Column-1)
+		if pos > file.FileEnd {
+			return nil, token.NoPos
+		}
+		return file, pos
+	}
+	return nil, token.NoPos
+}
@@ -0,0 +1,147 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import (
+	"go/token"
+	"strings"
+
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Diagnostic is a diagnostic generated by the engine. Besides the analysis.Diagnostic to be
+// reported, it also carries the structured conflict it is generated from (if any), such that
+// drivers can emit machine-readable outputs without parsing the message.
+type Diagnostic struct {
+	analysis.Diagnostic
+	// Conflict is the structured form of the conflict the diagnostic is generated from. It is nil
+	// if the diagnostic is not generated from a conflict (e.g., for internal errors).
+	Conflict *Conflict
+}
+
+// Conflict is the structured form of a conflict, i.e., a potential nil panic.
+type Conflict struct {
+	// Position is the position where the conflict is reported, i.e., the dereference point.
+	Position token.Position
+	// NilPath is the part of the nil flow going from the nilable source to the point of conflict,
+	// in program order.
+	NilPath []FlowStep
+	// NonNilPath is the part of the nil flow going from the point of conflict to the dereference
+	// point, in program order.
+	NonNilPath []FlowStep
+	// SimilarPositions are the dereference points of the other conflicts with the same nil path,
+	// which are grouped into this conflict for concise reporting.
+	SimilarPositions []token.Position
+}
+
+// Flow returns the complete nil flow of the conflict, i.e., the nil path followed by the non-nil
+// path.
+func (c *Conflict) Flow() []FlowStep {
+	flow := make([]FlowStep, 0, len(c.NilPath)+len(c.NonNilPath))
+	flow = append(flow, c.NilPath...)
+	return append(flow, c.NonNilPath...)
+}
+
+// FlowStep is a single step in the nil flow, where a value is produced and then consumed.
+type FlowStep struct {
+	// ProducerPosition is the position of the producer, which is invalid if the producer is
+	// artificial (i.e., not an authentic AST node in the source).
+	ProducerPosition token.Position
+	// ProducerRepr describes how the value is produced, e.g., "literal `nil`".
+	ProducerRepr string
+	// ConsumerPosition is the position of the consumer.
+	ConsumerPosition token.Position
+	// ConsumerRepr describes how the value is consumed, e.g., "dereferenced".
+	ConsumerRepr string
+}
+
+// Position returns the position of the step, i.e., the position of the consumer if it is valid,
+// and the position of the producer otherwise.
+func (s FlowStep) Position() token.Position {
+	if s.ConsumerPosition.IsValid() {
+		return s.ConsumerPosition
+	}
+	return s.ProducerPosition
+}
+
+// Repr returns the description of the step, i.e., the producer repr followed by the consumer repr.
+func (s FlowStep) Repr() string {
+	var reprs []string
+	for _, r := range [...]string{s.ProducerRepr, s.ConsumerRepr} {
+		if r != "" {
+			reprs = append(reprs, r)
+		}
+	}
+	return strings.Join(reprs, " ")
+}
+
+// structured converts the internal conflict to its structured form.
+func (e *Engine) structured(c conflict) *Conflict {
+	s := &Conflict{
+		Position:   e.pass.Fset.Position(c.pos),
+		NilPath:    e.flowSteps(c.flow.nilPath),
+		NonNilPath: e.flowSteps(c.flow.nonnilPath),
+	}
+	for _, similar := range c.similarConflicts {
+		s.SimilarPositions = append(s.SimilarPositions, e.pass.Fset.Position(similar.pos))
+	}
+	return s
+}
+
+// flowSteps converts the nodes in the nil flow to flow steps.
+func (e *Engine) flowSteps(nodes []node) []FlowStep {
+	if len(nodes) == 0 {
+		return nil
+	}
+	steps := make([]FlowStep, len(nodes))
+	for i, n := range nodes {
+		steps[i] = FlowStep{
+			ProducerPosition: e.untruncate(n.producerPosition),
+			ProducerRepr:     n.producerRepr,
+			ConsumerPosition: e.untruncate(n.consumerPosition),
+			ConsumerRepr:     n.consumerRepr,
+		}
+	}
+	return steps
+}
+
+// untruncate restores the full file name of the position, whose file name has been truncated for
+// printing (see [util.TruncatePosition]). It looks up the files in the file set whose names end
+// with the truncated name, and the position is returned as is if there is no unique match.
+func (e *Engine) untruncate(position token.Position) token.Position {
+	if !position.IsValid() {
+		return position
+	}
+
+	if e.fullNames == nil {
+		e.fullNames = make(map[string]string)
+		for _, info := range e.files {
+			full := info.file.Name()
+			truncated := util.TruncatePosition(token.Position{Filename: full}).Filename
+			if existing, ok := e.fullNames[truncated]; ok && existing != full {
+				// Mark the truncated name as ambiguous.
+				e.fullNames[truncated] = ""
+				continue
+			}
+			e.fullNames[truncated] = full
+		}
+	}
+
+	if full := e.fullNames[position.Filename]; full != "" {
+		position.Filename = full
+	}
+	return position
+}
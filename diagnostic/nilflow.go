@@ -80,6 +80,9 @@ func (n *node) String() string {
 	reasonStr := ""
 	if n.consumerPosition.IsValid() {
 		posStr = n.consumerPosition.String()
+	} else if n.producerPosition.IsValid() {
+		// Nodes explained by annotations only have the producer (i.e., the annotated site).
+		posStr = n.producerPosition.String()
 	}
 
 	if len(n.producerRepr) > 0 {
@@ -0,0 +1,139 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/token"
+)
+
+// The types below define the JSON form of the conflicts, which is meant to be stable for
+// downstream tooling. A conflict is encoded as the dereference position, the ordered list of steps
+// in its complete nil flow, and the positions of the similar conflicts grouped into it:
+//
+//	{
+//	  "position": {"filename": "foo.go", "line": 10, "column": 2},
+//	  "flow": [
+//	    {
+//	      "path": "nil",
+//	      "producer": {"repr": "literal `nil`", "position": {...}},
+//	      "consumer": {"repr": "returned from `foo()` in position 0", "position": {...}}
+//	    },
+//	    ...
+//	  ],
+//	  "similarPositions": [{...}, ...]
+//	}
+//
+// Positions are omitted if they are invalid (e.g., for artificial producers).
+type (
+	jsonConflict struct {
+		Position         *jsonPosition  `json:"position,omitempty"`
+		Flow             []jsonFlowStep `json:"flow"`
+		SimilarPositions []jsonPosition `json:"similarPositions,omitempty"`
+	}
+
+	jsonFlowStep struct {
+		Path     string      `json:"path"`
+		Producer jsonTrigger `json:"producer"`
+		Consumer jsonTrigger `json:"consumer"`
+	}
+
+	jsonTrigger struct {
+		Repr     string        `json:"repr,omitempty"`
+		Position *jsonPosition `json:"position,omitempty"`
+	}
+
+	jsonPosition struct {
+		Filename string `json:"filename"`
+		Line     int    `json:"line"`
+		Column   int    `json:"column"`
+	}
+)
+
+const (
+	_jsonNilPath    = "nil"
+	_jsonNonNilPath = "nonnil"
+)
+
+func newJSONPosition(position token.Position) *jsonPosition {
+	if !position.IsValid() {
+		return nil
+	}
+	return &jsonPosition{Filename: position.Filename, Line: position.Line, Column: position.Column}
+}
+
+func (p *jsonPosition) toPosition() token.Position {
+	if p == nil {
+		return token.Position{}
+	}
+	return token.Position{Filename: p.Filename, Line: p.Line, Column: p.Column}
+}
+
+// MarshalJSON encodes the conflict in its stable JSON form.
+func (c *Conflict) MarshalJSON() ([]byte, error) {
+	jc := jsonConflict{
+		Position: newJSONPosition(c.Position),
+		Flow:     make([]jsonFlowStep, 0, len(c.NilPath)+len(c.NonNilPath)),
+	}
+	for _, part := range [...]struct {
+		path  string
+		steps []FlowStep
+	}{{_jsonNilPath, c.NilPath}, {_jsonNonNilPath, c.NonNilPath}} {
+		for _, step := range part.steps {
+			jc.Flow = append(jc.Flow, jsonFlowStep{
+				Path:     part.path,
+				Producer: jsonTrigger{Repr: step.ProducerRepr, Position: newJSONPosition(step.ProducerPosition)},
+				Consumer: jsonTrigger{Repr: step.ConsumerRepr, Position: newJSONPosition(step.ConsumerPosition)},
+			})
+		}
+	}
+	for _, p := range c.SimilarPositions {
+		if jp := newJSONPosition(p); jp != nil {
+			jc.SimilarPositions = append(jc.SimilarPositions, *jp)
+		}
+	}
+	return json.Marshal(jc)
+}
+
+// UnmarshalJSON decodes the conflict from its stable JSON form.
+func (c *Conflict) UnmarshalJSON(data []byte) error {
+	var jc jsonConflict
+	if err := json.Unmarshal(data, &jc); err != nil {
+		return err
+	}
+
+	*c = Conflict{Position: jc.Position.toPosition()}
+	for _, js := range jc.Flow {
+		step := FlowStep{
+			ProducerPosition: js.Producer.Position.toPosition(),
+			ProducerRepr:     js.Producer.Repr,
+			ConsumerPosition: js.Consumer.Position.toPosition(),
+			ConsumerRepr:     js.Consumer.Repr,
+		}
+		switch js.Path {
+		case _jsonNilPath:
+			c.NilPath = append(c.NilPath, step)
+		case _jsonNonNilPath:
+			c.NonNilPath = append(c.NonNilPath, step)
+		default:
+			return fmt.Errorf("unknown path %q for flow step", js.Path)
+		}
+	}
+	for _, p := range jc.SimilarPositions {
+		c.SimilarPositions = append(c.SimilarPositions, p.toPosition())
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import (
+	"encoding/json"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConflictJSON(t *testing.T) {
+	t.Parallel()
+
+	c := &Conflict{
+		Position: token.Position{Filename: "foo.go", Line: 10, Column: 2},
+		NilPath: []FlowStep{{
+			ProducerPosition: token.Position{Filename: "bar.go", Line: 3, Column: 9},
+			ProducerRepr:     "literal `nil`",
+			ConsumerPosition: token.Position{Filename: "bar.go", Line: 3, Column: 2},
+			ConsumerRepr:     "returned from `bar()` in position 0",
+		}},
+		NonNilPath: []FlowStep{{
+			ProducerRepr:     "result 0 of `bar()`",
+			ConsumerPosition: token.Position{Filename: "foo.go", Line: 10, Column: 2},
+			ConsumerRepr:     "dereferenced",
+		}},
+		SimilarPositions: []token.Position{{Filename: "foo.go", Line: 12, Column: 2}},
+	}
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"position": {"filename": "foo.go", "line": 10, "column": 2},
+		"flow": [
+			{
+				"path": "nil",
+				"producer": {"repr": "literal `+"`nil`"+`", "position": {"filename": "bar.go", "line": 3, "column": 9}},
+				"consumer": {"repr": "returned from `+"`bar()`"+` in position 0", "position": {"filename": "bar.go", "line": 3, "column": 2}}
+			},
+			{
+				"path": "nonnil",
+				"producer": {"repr": "result 0 of `+"`bar()`"+`"},
+				"consumer": {"repr": "dereferenced", "position": {"filename": "foo.go", "line": 10, "column": 2}}
+			}
+		],
+		"similarPositions": [{"filename": "foo.go", "line": 12, "column": 2}]
+	}`, string(data))
+
+	// The conflict should survive a round trip.
+	var decoded Conflict
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, c, &decoded)
+
+	// Unknown paths should be rejected.
+	require.Error(t, json.Unmarshal([]byte(`{"flow": [{"path": "foo"}]}`), &decoded))
+}
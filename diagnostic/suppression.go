@@ -0,0 +1,167 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diagnostic
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"go.uber.org/nilaway/util"
+	"golang.org/x/tools/go/analysis"
+)
+
+// IgnoreDirective is the comment directive that suppresses NilAway diagnostics. It can optionally
+// be followed by a free-form reason, e.g., `//nilaway:ignore value is set by the framework`.
+const IgnoreDirective = "//nilaway:ignore"
+
+// suppression is a single `//nilaway:ignore` directive found in the source code.
+type suppression struct {
+	pos    token.Pos
+	reason string
+	// used is set to true if the suppression has matched at least one conflict.
+	used bool
+}
+
+// lineKey identifies a line in a file. Note that the file name is truncated (see
+// [util.TruncatePosition]) since the positions stored in the nil flows are truncated as well.
+type lineKey struct {
+	filename string
+	line     int
+}
+
+func newLineKey(position token.Position) lineKey {
+	position = util.TruncatePosition(position)
+	return lineKey{filename: position.Filename, line: position.Line}
+}
+
+// suppressions stores all `//nilaway:ignore` directives in the current package, indexed by the
+// lines they apply to. Multiple directives may apply to the same line, e.g., a directive on a line
+// of its own followed by a trailing directive on the next line.
+type suppressions struct {
+	byLine map[lineKey][]*suppression
+	// all stores the suppressions in the order they appear in the source for deterministic reports.
+	all []*suppression
+}
+
+// collectSuppressions scans the comments in the files of the current package for
+// `//nilaway:ignore` directives. A directive applies to the line it appears on; if the directive
+// is on a line of its own, it applies to the following line as well.
+func collectSuppressions(pass *analysis.Pass) *suppressions {
+	s := &suppressions{byLine: make(map[lineKey][]*suppression)}
+	for _, file := range pass.Files {
+		// codeLines is lazily computed since most files do not contain any directives.
+		var codeLines map[int]bool
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				reason, ok := parseIgnoreDirective(comment.Text)
+				if !ok {
+					continue
+				}
+
+				sup := &suppression{pos: comment.Pos(), reason: reason}
+				s.all = append(s.all, sup)
+
+				position := pass.Fset.Position(comment.Pos())
+				key := newLineKey(position)
+				s.byLine[key] = append(s.byLine[key], sup)
+
+				if codeLines == nil {
+					codeLines = linesWithCode(pass.Fset, file)
+				}
+				if !codeLines[position.Line] {
+					position.Line++
+					key := newLineKey(position)
+					s.byLine[key] = append(s.byLine[key], sup)
+				}
+			}
+		}
+	}
+	return s
+}
+
+// parseIgnoreDirective returns the (possibly empty) reason and true if the comment text is a
+// `//nilaway:ignore` directive, and false otherwise.
+func parseIgnoreDirective(text string) (string, bool) {
+	rest, ok := strings.CutPrefix(text, IgnoreDirective)
+	if !ok {
+		return "", false
+	}
+	// The directive must be followed by nothing or a whitespace-separated reason, such that
+	// comments like `//nilaway:ignored` are not treated as directives.
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// linesWithCode returns the set of lines in the file where a (non-comment) AST node starts or
+// ends, which is used to tell trailing directives apart from the ones on a line of their own.
+func linesWithCode(fset *token.FileSet, file *ast.File) map[int]bool {
+	lines := make(map[int]bool)
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case nil, *ast.Comment, *ast.CommentGroup:
+			return false
+		}
+		lines[fset.Position(n.Pos()).Line] = true
+		lines[fset.Position(n.End()).Line] = true
+		return true
+	})
+	return lines
+}
+
+// match returns true if any of the suppressions applies to the given position, and marks all the
+// matched suppressions as used.
+func (s *suppressions) match(position token.Position) bool {
+	if !position.IsValid() {
+		return false
+	}
+	sups := s.byLine[newLineKey(position)]
+	for _, sup := range sups {
+		sup.used = true
+	}
+	return len(sups) > 0
+}
+
+// suppresses returns true if the conflict is suppressed by a directive on its dereference line
+// (i.e., the reporting position) or its nil source line (i.e., the first node in the nil flow).
+func (s *suppressions) suppresses(c conflict, derefPosition token.Position) bool {
+	if len(s.all) == 0 {
+		return false
+	}
+
+	// Note that we intentionally do not short-circuit here, such that both the directives on the
+	// dereference line and the nil source line are marked as used.
+	suppressed := s.match(derefPosition)
+
+	var source *node
+	if len(c.flow.nilPath) > 0 {
+		source = &c.flow.nilPath[0]
+	} else if len(c.flow.nonnilPath) > 0 {
+		source = &c.flow.nonnilPath[0]
+	}
+	if source != nil {
+		sourcePosition := source.producerPosition
+		if !sourcePosition.IsValid() {
+			sourcePosition = source.consumerPosition
+		}
+		// The dereference line may coincide with the nil source line, which has been checked.
+		if newLineKey(sourcePosition) != newLineKey(derefPosition) {
+			suppressed = s.match(sourcePosition) || suppressed
+		}
+	}
+	return suppressed
+}
@@ -19,6 +19,9 @@ package inference
 import (
 	"encoding/gob"
 	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
 	"strings"
 
 	"go.uber.org/nilaway/annotation"
@@ -49,10 +52,10 @@ type Engine struct {
 	// primitive is the primitivizer that is able to convert full triggers and annotation sites to
 	// their primitive forms (see primitive.go).
 	primitive *primitivizer
-	// controlledTriggersBySite stores the set of controlled triggers for each site if the site
-	// controls any triggers. This field is for internal use in the struct only and should not be
-	// accessed elsewhere.
-	controlledTriggersBySite map[primitiveSite]map[annotation.FullTrigger]bool
+	// controlledTriggersBySite stores the controlled triggers (in the canonical order, see
+	// Engine.sortFullTriggers) for each site if the site controls any triggers. This field is for
+	// internal use in the struct only and should not be accessed elsewhere.
+	controlledTriggersBySite map[primitiveSite][]annotation.FullTrigger
 }
 
 // NewEngine constructs an inference engine that is ready to run inference.
@@ -135,7 +138,22 @@ func (e *Engine) ObserveUpstream() {
 // In this latter case, the subsequent calls to observeAssertion below cannot determine any local
 // annotation sites, because they're all already determined, but they can yield failures.
 func (e *Engine) ObserveAnnotations(pkgAnnotations *annotation.ObservedMap, mode ModeOfInference) {
+	e.observeAnnotations(pkgAnnotations, mode, false /* skipLocal */)
+}
+
+// ObserveNonLocalAnnotations is similar to ObserveAnnotations, except that it skips the
+// annotations of the sites declared in the current package (e.g., keeping only the ones from the
+// annotation stubs of other packages). This allows the inference to form its own view of the
+// current package that can be compared against the hand-written annotations.
+func (e *Engine) ObserveNonLocalAnnotations(pkgAnnotations *annotation.ObservedMap, mode ModeOfInference) {
+	e.observeAnnotations(pkgAnnotations, mode, true /* skipLocal */)
+}
+
+func (e *Engine) observeAnnotations(pkgAnnotations *annotation.ObservedMap, mode ModeOfInference, skipLocal bool) {
 	pkgAnnotations.Range(func(key annotation.Key, isDeep bool, val bool) {
+		if skipLocal && key.Object().Pkg() == e.pass.Pkg {
+			return
+		}
 		site := e.primitive.site(key, isDeep)
 		if val {
 			e.observeSiteExplanation(site, TrueBecauseAnnotation{AnnotationPos: site.Position})
@@ -154,6 +172,12 @@ func (e *Engine) ObserveAnnotations(pkgAnnotations *annotation.ObservedMap, mode
 // observeImplication. Before all assertions are sorted and handled thus, the annotations read for
 // the package are iterated over and observed via calls to observeSiteExplanation as a <Val>BecauseAnnotation.
 func (e *Engine) ObservePackage(pkgFullTriggers []annotation.FullTrigger) {
+	// The triggers are observed in a canonical order, since the inference results (e.g., the
+	// explanations of the determined sites and the conflicts found) depend on the order of the
+	// observations. This makes the results deterministic regardless of the order the triggers are
+	// generated in (e.g., by the concurrent analyses of the functions).
+	pkgFullTriggers = e.sortFullTriggers(pkgFullTriggers)
+
 	// Separate out triggers with UseAsNonErrorRetDependentOnErrorRetNilability consumer from other triggers.
 	// This is needed since whether UseAsNonErrorRetDependentOnErrorRetNilability triggers should be fired
 	// is dependent on their corresponding UseAsErrorRetWithNilabilityUnknown triggers. By this separation,
@@ -232,23 +256,217 @@ func (e *Engine) ObservePackage(pkgFullTriggers []annotation.FullTrigger) {
 	e.buildPkgInferenceMap(filteredTriggers)
 }
 
+// triggerOrderKey is the key of a full trigger for sorting the full triggers in a canonical order
+// (see Engine.sortFullTriggers), which only depends on the contents of the full trigger.
+type triggerOrderKey struct {
+	// inFunc is true if the consumer of the full trigger is in a function (declaration or literal),
+	// and funcPos is the position of the innermost such function.
+	inFunc                   bool
+	funcPos                  token.Position
+	consumerPos, producerPos token.Position
+	// consumerAlways is true if the consumer always fires (e.g., a dereference).
+	consumerAlways             bool
+	consumerRepr, producerRepr string
+	consumerSite, producerSite string
+	controllerSite             string
+	createdFromDuplication     bool
+	// The fields below only serve as the final tiebreakers, such that two keys are tied only if
+	// the full triggers are indistinguishable to the engine: the kinds and the (dynamic) types of
+	// the annotations, and the ranges of the expressions of the producer and the consumer.
+	consumerKind, producerKind annotation.TriggerKind
+	consumerType, producerType string
+	consumerExprPos            token.Position
+	consumerExprEnd            token.Position
+	producerExprEnd            token.Position
+}
+
+// less returns true if the key is ordered before the other key. The canonical order follows the
+// order the full triggers used to be generated in: the functions are ordered by their positions
+// (hence function literals come after their enclosing functions), followed by the triggers not in
+// any function (e.g., from the global variables); the triggers in a function are in the reverse
+// order of their positions, i.e., the order of the backpropagation, where the triggers with
+// consumers that always fire (e.g., dereferences) come after the others at the same positions.
+func (k *triggerOrderKey) less(other *triggerOrderKey) bool {
+	if k.inFunc != other.inFunc {
+		return k.inFunc
+	}
+	if c := comparePositions(k.funcPos, other.funcPos); c != 0 {
+		return c < 0
+	}
+	if c := comparePositions(k.consumerPos, other.consumerPos); c != 0 {
+		return c > 0
+	}
+	if c := comparePositions(k.producerPos, other.producerPos); c != 0 {
+		return c > 0
+	}
+	if k.consumerAlways != other.consumerAlways {
+		return other.consumerAlways
+	}
+	for _, s := range [...][2]string{
+		{k.consumerRepr, other.consumerRepr},
+		{k.producerRepr, other.producerRepr},
+		{k.consumerSite, other.consumerSite},
+		{k.producerSite, other.producerSite},
+		{k.controllerSite, other.controllerSite},
+	} {
+		if s[0] != s[1] {
+			return s[0] < s[1]
+		}
+	}
+	if k.createdFromDuplication != other.createdFromDuplication {
+		return other.createdFromDuplication
+	}
+
+	// Final tiebreakers.
+	if k.consumerKind != other.consumerKind {
+		return k.consumerKind < other.consumerKind
+	}
+	if k.producerKind != other.producerKind {
+		return k.producerKind < other.producerKind
+	}
+	for _, s := range [...][2]string{
+		{k.consumerType, other.consumerType},
+		{k.producerType, other.producerType},
+	} {
+		if s[0] != s[1] {
+			return s[0] < s[1]
+		}
+	}
+	for _, p := range [...][2]token.Position{
+		{k.consumerExprPos, other.consumerExprPos},
+		{k.consumerExprEnd, other.consumerExprEnd},
+		{k.producerExprEnd, other.producerExprEnd},
+	} {
+		if c := comparePositions(p[0], p[1]); c != 0 {
+			return c < 0
+		}
+	}
+	return false
+}
+
+// comparePositions compares the positions by their file names and then offsets.
+func comparePositions(a, b token.Position) int {
+	if a.Filename != b.Filename {
+		if a.Filename < b.Filename {
+			return -1
+		}
+		return 1
+	}
+	return a.Offset - b.Offset
+}
+
+// funcRange is the range of a function declaration or literal in the source.
+type funcRange struct {
+	pos, end token.Pos
+}
+
+// funcRanges returns the ranges of all function declarations (with bodies) and literals in the
+// files of the package, sorted by their starting positions.
+func funcRanges(files []*ast.File) []funcRange {
+	ranges := make([]funcRange, 0, len(files))
+	for _, file := range files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				if node.Body != nil {
+					ranges = append(ranges, funcRange{pos: node.Pos(), end: node.End()})
+				}
+			case *ast.FuncLit:
+				ranges = append(ranges, funcRange{pos: node.Pos(), end: node.End()})
+			}
+			return true
+		})
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].pos < ranges[j].pos })
+	return ranges
+}
+
+// enclosingFunc returns the range of the innermost function containing the position, and false
+// if there is none. The ranges must be sorted as returned by funcRanges.
+func enclosingFunc(ranges []funcRange, pos token.Pos) (funcRange, bool) {
+	// Functions are properly nested, so the innermost function containing the position is the
+	// last one starting at or before the position that has not ended yet.
+	i := sort.Search(len(ranges), func(i int) bool { return ranges[i].pos > pos })
+	for i--; i >= 0; i-- {
+		if pos < ranges[i].end {
+			return ranges[i], true
+		}
+	}
+	return funcRange{}, false
+}
+
+// sortFullTriggers returns a copy of the full triggers sorted in a canonical order (see
+// triggerOrderKey.less), such that the inference results do not depend on the order the full
+// triggers are generated in. The order is total up to the full triggers with equal keys, which
+// have the same producer and consumer sites, kinds, annotation types, expression ranges and
+// explanations, i.e., they are indistinguishable to the engine, hence the order between them does
+// not affect the results.
+func (e *Engine) sortFullTriggers(triggers []annotation.FullTrigger) []annotation.FullTrigger {
+	siteKey := func(site annotation.Key, kind annotation.TriggerKind) string {
+		if site == nil {
+			return ""
+		}
+		return fmt.Sprint(e.primitive.site(site, kind == annotation.DeepConditional))
+	}
+	ranges := funcRanges(e.pass.Files)
+
+	keys := make([]triggerOrderKey, len(triggers))
+	indices := make([]int, len(triggers))
+	for i, t := range triggers {
+		indices[i] = i
+		producerRepr, consumerRepr := t.Prestrings(e.pass)
+		keys[i] = triggerOrderKey{
+			consumerPos:            e.primitive.toPosition(t.Consumer.Pos()),
+			consumerAlways:         t.Consumer.Annotation.Kind() == annotation.Always,
+			consumerRepr:           consumerRepr.String(),
+			producerRepr:           producerRepr.String(),
+			consumerSite:           siteKey(t.Consumer.Annotation.UnderlyingSite(), t.Consumer.Annotation.Kind()),
+			producerSite:           siteKey(t.Producer.Annotation.UnderlyingSite(), t.Producer.Annotation.Kind()),
+			createdFromDuplication: t.CreatedFromDuplication,
+			consumerKind:           t.Consumer.Annotation.Kind(),
+			producerKind:           t.Producer.Annotation.Kind(),
+			consumerType:           fmt.Sprintf("%T", t.Consumer.Annotation),
+			producerType:           fmt.Sprintf("%T", t.Producer.Annotation),
+		}
+		if fn, ok := enclosingFunc(ranges, t.Consumer.Pos()); ok {
+			keys[i].inFunc, keys[i].funcPos = true, e.primitive.toPosition(fn.pos)
+		}
+		if t.Consumer.Expr != nil {
+			keys[i].consumerExprPos = e.primitive.toPosition(t.Consumer.Expr.Pos())
+			keys[i].consumerExprEnd = e.primitive.toPosition(t.Consumer.Expr.End())
+		}
+		if t.Producer.Expr != nil {
+			keys[i].producerPos = e.primitive.toPosition(t.Producer.Expr.Pos())
+			keys[i].producerExprEnd = e.primitive.toPosition(t.Producer.Expr.End())
+		}
+		if t.Controller != nil {
+			keys[i].controllerSite = siteKey(t.Controller, annotation.Conditional)
+		}
+	}
+	sort.SliceStable(indices, func(i, j int) bool { return keys[indices[i]].less(&keys[indices[j]]) })
+
+	sorted := make([]annotation.FullTrigger, len(triggers))
+	for i, index := range indices {
+		sorted[i] = triggers[index]
+	}
+	return sorted
+}
+
 func (e *Engine) buildPkgInferenceMap(triggers []annotation.FullTrigger) {
-	// Map each site to all the triggers controlled by the site
-	controlledTgsBySite := map[primitiveSite]map[annotation.FullTrigger]bool{}
+	// Map each site to all the triggers controlled by the site. The triggers are kept in the
+	// (canonical) order they are given in, such that they are activated in a deterministic order.
+	controlledTgsBySite := map[primitiveSite][]annotation.FullTrigger{}
+	seen := map[annotation.FullTrigger]bool{}
 	for _, trigger := range triggers {
-		if !trigger.Controlled() {
+		if !trigger.Controlled() || seen[trigger] {
 			continue
 		}
+		seen[trigger] = true
 		// controller is an CallSiteParamAnnotationKey, which must be enclosed in a ArgPass
 		// consumer, which Kind() method returns Conditional which is not deep. Thus, we pass false
 		// here.
 		site := e.primitive.site(trigger.Controller, false)
-		ts, ok := controlledTgsBySite[site]
-		if !ok {
-			ts = map[annotation.FullTrigger]bool{}
-			controlledTgsBySite[site] = ts
-		}
-		ts[trigger] = true
+		controlledTgsBySite[site] = append(controlledTgsBySite[site], trigger)
 	}
 	e.controlledTriggersBySite = controlledTgsBySite
 
@@ -401,7 +619,7 @@ func (e *Engine) storeDeterminedAndActivateControlledTriggers(site primitiveSite
 // to be a new value.
 func (e *Engine) activateControlledTriggers(site primitiveSite, siteExplained ExplainedBool) {
 	if controlledTgs, ok := e.controlledTriggersBySite[site]; ok && siteExplained.Val() {
-		for tg := range controlledTgs {
+		for _, tg := range controlledTgs {
 			e.buildFromSingleFullTrigger(tg)
 		}
 	}
@@ -569,4 +787,10 @@ func GobRegister() {
 	gob.RegisterName(nextStr(), annotation.MethodRecvDeepPrestring{})
 	gob.RegisterName(nextStr(), annotation.FldReturnPrestring{})
 	gob.RegisterName(nextStr(), annotation.UseAsReturnDeepPrestring{})
+	gob.RegisterName(nextStr(), annotation.FuncReturnNonnilByContractPrestring{})
+	gob.RegisterName(nextStr(), annotation.FuncReturnNilByContractPrestring{})
+	gob.RegisterName(nextStr(), annotation.TypeAssertPrestring{})
+	gob.RegisterName(nextStr(), annotation.FailedTypeAssertPrestring{})
+	gob.RegisterName(nextStr(), annotation.ChanSentToPrestring{})
+	gob.RegisterName(nextStr(), annotation.ChanClosePrestring{})
 }
@@ -0,0 +1,88 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import (
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/nilaway/annotation"
+)
+
+func TestTriggerOrderKeyLess(t *testing.T) {
+	t.Parallel()
+
+	pos := func(offset int) token.Position {
+		return token.Position{Filename: "foo.go", Offset: offset, Line: 1, Column: offset + 1}
+	}
+	base := triggerOrderKey{
+		inFunc:          true,
+		funcPos:         pos(0),
+		consumerPos:     pos(10),
+		producerPos:     pos(5),
+		consumerRepr:    "dereferenced",
+		producerRepr:    "literal `nil`",
+		consumerSite:    "consumer",
+		producerSite:    "producer",
+		consumerKind:    annotation.Conditional,
+		producerKind:    annotation.Always,
+		consumerType:    "*annotation.PtrLoad",
+		producerType:    "*annotation.ConstNil",
+		consumerExprPos: pos(10),
+		consumerExprEnd: pos(12),
+		producerExprEnd: pos(8),
+	}
+
+	// Each key differs from the base key in exactly one field.
+	keys := []triggerOrderKey{base}
+	for _, modify := range []func(k *triggerOrderKey){
+		func(k *triggerOrderKey) { k.inFunc = false },
+		func(k *triggerOrderKey) { k.funcPos = pos(1) },
+		func(k *triggerOrderKey) { k.consumerPos = pos(11) },
+		func(k *triggerOrderKey) { k.producerPos = pos(6) },
+		func(k *triggerOrderKey) { k.consumerAlways = true },
+		func(k *triggerOrderKey) { k.consumerRepr = "accessed field `f`" },
+		func(k *triggerOrderKey) { k.producerRepr = "unassigned variable `x`" },
+		func(k *triggerOrderKey) { k.consumerSite = "other consumer" },
+		func(k *triggerOrderKey) { k.producerSite = "other producer" },
+		func(k *triggerOrderKey) { k.controllerSite = "controller" },
+		func(k *triggerOrderKey) { k.createdFromDuplication = true },
+		func(k *triggerOrderKey) { k.consumerKind = annotation.DeepConditional },
+		func(k *triggerOrderKey) { k.producerKind = annotation.Conditional },
+		func(k *triggerOrderKey) { k.consumerType = "*annotation.FldAccess" },
+		func(k *triggerOrderKey) { k.producerType = "*annotation.NoVarAssign" },
+		func(k *triggerOrderKey) { k.consumerExprPos = pos(9) },
+		func(k *triggerOrderKey) { k.consumerExprEnd = pos(14) },
+		func(k *triggerOrderKey) { k.producerExprEnd = pos(7) },
+	} {
+		k := base
+		modify(&k)
+		keys = append(keys, k)
+	}
+
+	// The order must be strict and total, i.e., exactly one of two distinct keys is ordered before
+	// the other, such that the sorted order does not depend on the input order.
+	for i := range keys {
+		require.False(t, keys[i].less(&keys[i]), "key %d is ordered before itself", i)
+		for j := range keys {
+			if i == j {
+				continue
+			}
+			require.NotEqual(t, keys[i].less(&keys[j]), keys[j].less(&keys[i]),
+				"keys %d and %d are not strictly ordered", i, j)
+		}
+	}
+}
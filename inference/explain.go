@@ -0,0 +1,88 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/util"
+	"go.uber.org/nilaway/util/orderedmap"
+)
+
+// Explain returns a human-readable explanation of the inferred value of the shallow or deep site
+// of the key: for a determined site, the chain of reasons (see ExplainedBool) from the nil source
+// to the site (if nilable) or from the site to the dereference (if nonnil); for an undetermined
+// site, the implications to and from the site observed so far.
+func (i *InferredMap) Explain(key annotation.Key, isDeep bool) string {
+	site := i.primitive.site(key, isDeep)
+	repr := site.Repr
+	if isDeep {
+		repr = "deep " + repr
+	}
+
+	val, ok := i.mapping.Load(site)
+	if !ok {
+		return fmt.Sprintf("%s is not constrained by any observed assertion", repr)
+	}
+
+	var b strings.Builder
+	switch val := val.(type) {
+	case *DeterminedVal:
+		nilability := "NONNIL"
+		if val.Bool.Val() {
+			nilability = "NILABLE"
+		}
+		fmt.Fprintf(&b, "%s is determined %s:", repr, nilability)
+
+		steps := make([]string, 0, 1)
+		for r := val.Bool; r != nil; r = r.DeeperReason() {
+			step := r.String()
+			if producer, consumer := r.TriggerReprs(); producer != nil && consumer != nil {
+				step = producer.String() + " " + consumer.String()
+			}
+			steps = append(steps, fmt.Sprintf("\n\t- %s: %s", util.TruncatePosition(r.Position()), step))
+		}
+		// Similar to the nil flows in the diagnostics, the reasons for nilable sites are chained
+		// backwards from the site to the nil source, so we reverse them to follow the program flow.
+		if val.Bool.Val() {
+			for l, r := 0, len(steps)-1; l < r; l, r = l+1, r-1 {
+				steps[l], steps[r] = steps[r], steps[l]
+			}
+		}
+		b.WriteString(strings.Join(steps, ""))
+	case *UndeterminedVal:
+		fmt.Fprintf(&b, "%s is still undetermined", repr)
+		writeEdges := func(title string, edges *orderedmap.OrderedMap[primitiveSite, primitiveFullTrigger]) {
+			if len(edges.Pairs) == 0 {
+				return
+			}
+			fmt.Fprintf(&b, "\n%s:", title)
+			for _, p := range edges.Pairs {
+				other, trigger := p.Key, p.Value
+				otherRepr := other.Repr
+				if other.IsDeep {
+					otherRepr = "deep " + otherRepr
+				}
+				fmt.Fprintf(&b, "\n\t- %s: %s (%s %s)", util.TruncatePosition(trigger.Position), otherRepr,
+					trigger.ProducerRepr, trigger.ConsumerRepr)
+			}
+		}
+		writeEdges("it is NILABLE if any of the following is NILABLE", val.Implicants)
+		writeEdges("it is NONNIL if any of the following is NONNIL", val.Implicates)
+	}
+	return b.String()
+}
@@ -0,0 +1,266 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"strings"
+
+	"go.uber.org/nilaway/annotation"
+	"go.uber.org/nilaway/util"
+)
+
+// ImplicationGraph is a serializable snapshot of (part of) the InferredMap for debugging purposes:
+// the determined sites along with the reasons, and the implication edges between the undetermined
+// sites along with the assertions that justified them.
+type ImplicationGraph struct {
+	// PkgPath is the path of the package being analyzed.
+	PkgPath string `json:"pkgPath"`
+	// Sites is the list of sites in the graph, where the index of a site is its ID.
+	Sites []GraphSite `json:"sites"`
+	// Edges is the list of implication edges between the sites.
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphSite is a site in the ImplicationGraph.
+type GraphSite struct {
+	// ID is the index of the site in ImplicationGraph.Sites.
+	ID int `json:"id"`
+	// Site is the string representation of the site (see primitiveSite.String).
+	Site string `json:"site"`
+	// PkgPath is the path of the package the site resides in.
+	PkgPath string `json:"pkgPath"`
+	// Position is the position of the declaration of the site.
+	Position string `json:"position"`
+	// Nilability is "NILABLE" or "NONNIL" for determined sites, or empty for undetermined ones.
+	Nilability string `json:"nilability,omitempty"`
+	// Reason is the reason why the site is determined (see ExplainedBool), or empty for
+	// undetermined sites.
+	Reason string `json:"reason,omitempty"`
+}
+
+// GraphEdge is an implication edge in the ImplicationGraph: the site `From` being nilable implies
+// that the site `To` is nilable (and, equivalently, `To` being nonnil implies `From` is nonnil).
+type GraphEdge struct {
+	// From is the ID of the implicant site.
+	From int `json:"from"`
+	// To is the ID of the implicate site.
+	To int `json:"to"`
+	// Position is the position of the assertion that justified the edge.
+	Position string `json:"position"`
+	// Producer is the string representation of the producer of the assertion.
+	Producer string `json:"producer"`
+	// Consumer is the string representation of the consumer of the assertion.
+	Consumer string `json:"consumer"`
+}
+
+// ImplicationGraph returns the graph of the sites residing in the current package (along with
+// the sites of other packages they are directly implicated with). If fn is not nil, the graph is
+// instead restricted to the sites reachable (following the edges in either direction) from the
+// function, i.e., its params, results and receiver, and the sites constrained by the assertions
+// within its body.
+func (i *InferredMap) ImplicationGraph(fn *ast.FuncDecl) *ImplicationGraph {
+	pass := i.primitive.pass
+	graph := &ImplicationGraph{PkgPath: pass.Pkg.Path(), Sites: []GraphSite{}, Edges: []GraphEdge{}}
+
+	included := make(map[primitiveSite]bool)
+	if fn == nil {
+		i.OrderedRange(func(site primitiveSite, val InferredVal) bool {
+			if site.PkgPath != graph.PkgPath {
+				return true
+			}
+			included[site] = true
+			if val, ok := val.(*UndeterminedVal); ok {
+				for _, p := range val.Implicants.Pairs {
+					included[p.Key] = true
+				}
+				for _, p := range val.Implicates.Pairs {
+					included[p.Key] = true
+				}
+			}
+			return true
+		})
+	} else {
+		i.markReachableFromFunc(fn, included)
+	}
+
+	// Assign the IDs in the (deterministic) insertion order of the map.
+	ids := make(map[primitiveSite]int)
+	i.OrderedRange(func(site primitiveSite, val InferredVal) bool {
+		if !included[site] {
+			return true
+		}
+		ids[site] = len(graph.Sites)
+		s := GraphSite{
+			ID:       len(graph.Sites),
+			Site:     site.String(),
+			PkgPath:  site.PkgPath,
+			Position: util.TruncatePosition(site.Position).String(),
+		}
+		if val, ok := val.(*DeterminedVal); ok {
+			s.Nilability = "NONNIL"
+			if val.Bool.Val() {
+				s.Nilability = "NILABLE"
+			}
+			s.Reason = val.Bool.String()
+		}
+		graph.Sites = append(graph.Sites, s)
+		return true
+	})
+
+	i.OrderedRange(func(site primitiveSite, val InferredVal) bool {
+		from, ok := ids[site]
+		undetermined, isUndetermined := val.(*UndeterminedVal)
+		if !ok || !isUndetermined {
+			return true
+		}
+		for _, p := range undetermined.Implicates.Pairs {
+			to, ok := ids[p.Key]
+			if !ok {
+				continue
+			}
+			graph.Edges = append(graph.Edges, GraphEdge{
+				From:     from,
+				To:       to,
+				Position: util.TruncatePosition(p.Value.Position).String(),
+				Producer: p.Value.ProducerRepr.String(),
+				Consumer: p.Value.ConsumerRepr.String(),
+			})
+		}
+		return true
+	})
+	return graph
+}
+
+// markReachableFromFunc marks the sites reachable from the function (see ImplicationGraph) in the
+// set.
+func (i *InferredMap) markReachableFromFunc(fn *ast.FuncDecl, included map[primitiveSite]bool) {
+	// The sites constrained by the assertions within the function body are found by the positions
+	// of the assertions, which are recorded in primitive forms.
+	start, end := i.primitive.toPosition(fn.Pos()), i.primitive.toPosition(fn.End())
+	within := func(pos token.Position) bool {
+		return pos.Filename == start.Filename && start.Offset <= pos.Offset && pos.Offset < end.Offset
+	}
+
+	var worklist []primitiveSite
+	if funcObj, ok := i.primitive.pass.TypesInfo.ObjectOf(fn.Name).(*types.Func); ok {
+		sig := funcObj.Type().(*types.Signature)
+		keys := make([]annotation.Key, 0, sig.Params().Len()+sig.Results().Len()+1)
+		for n := 0; n < sig.Params().Len(); n++ {
+			keys = append(keys, annotation.ParamKeyFromArgNum(funcObj, n))
+		}
+		for n := 0; n < sig.Results().Len(); n++ {
+			keys = append(keys, annotation.RetKeyFromRetNum(funcObj, n))
+		}
+		if sig.Recv() != nil {
+			keys = append(keys, &annotation.RecvAnnotationKey{FuncDecl: funcObj})
+		}
+		for _, key := range keys {
+			worklist = append(worklist, i.primitive.site(key, false /* isDeep */), i.primitive.site(key, true /* isDeep */))
+		}
+	}
+	i.OrderedRange(func(site primitiveSite, val InferredVal) bool {
+		switch val := val.(type) {
+		case *DeterminedVal:
+			for r := val.Bool; r != nil; r = r.DeeperReason() {
+				if within(r.Position()) {
+					worklist = append(worklist, site)
+					break
+				}
+			}
+		case *UndeterminedVal:
+			for _, p := range val.Implicates.Pairs {
+				if within(p.Value.Position) {
+					worklist = append(worklist, site, p.Key)
+				}
+			}
+		}
+		return true
+	})
+
+	for len(worklist) > 0 {
+		site := worklist[len(worklist)-1]
+		worklist = worklist[:len(worklist)-1]
+		val, ok := i.mapping.Load(site)
+		if !ok || included[site] {
+			continue
+		}
+		included[site] = true
+		if val, ok := val.(*UndeterminedVal); ok {
+			for _, p := range val.Implicants.Pairs {
+				worklist = append(worklist, p.Key)
+			}
+			for _, p := range val.Implicates.Pairs {
+				worklist = append(worklist, p.Key)
+			}
+		}
+	}
+}
+
+// WriteDOT writes the graph in the Graphviz DOT format, where the determined sites are colored
+// (red for nilable and green for nonnil) and the edges are labeled with the assertions.
+func (g *ImplicationGraph) WriteDOT(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "digraph %s {\n\tnode [shape=box];\n", dotQuote(g.PkgPath)); err != nil {
+		return err
+	}
+	for _, s := range g.Sites {
+		attrs := ""
+		switch s.Nilability {
+		case "NILABLE":
+			attrs = ", style=filled, fillcolor=salmon, tooltip=" + dotQuote(s.Reason)
+		case "NONNIL":
+			attrs = ", style=filled, fillcolor=palegreen, tooltip=" + dotQuote(s.Reason)
+		}
+		label := dotQuote(s.Site + "\n" + s.Position)
+		if _, err := fmt.Fprintf(w, "\t%d [label=%s%s];\n", s.ID, label, attrs); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges {
+		label := dotQuote(fmt.Sprintf("%s\n%s\n%s", e.Position, e.Producer, e.Consumer))
+		if _, err := fmt.Fprintf(w, "\t%d -> %d [label=%s];\n", e.From, e.To, label); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+// dotQuote returns the string as a double-quoted DOT string. Only `"` and `\` are escaped, and
+// the newlines are written as `\n` (i.e., a centered line break in labels). Unlike Go's %q, the
+// other characters (e.g., non-ASCII ones) are kept as is, since DOT does not understand the other
+// escape sequences of Go.
+func dotQuote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,70 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDotQuote(t *testing.T) {
+	t.Parallel()
+
+	testcases := []struct {
+		description string
+		s           string
+		want        string
+	}{
+		{description: "empty", s: "", want: `""`},
+		{description: "plain", s: "Result 0 of Function f", want: `"Result 0 of Function f"`},
+		{description: "quotes", s: `field "f"`, want: `"field \"f\""`},
+		{description: "backslashes", s: `C:\path\n`, want: `"C:\\path\\n"`},
+		{description: "newlines", s: "site\nfile.go:1:2", want: `"site\nfile.go:1:2"`},
+		{description: "non-ASCII", s: "héllo\tworld", want: "\"héllo\tworld\""},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.want, dotQuote(tc.s))
+		})
+	}
+}
+
+func TestImplicationGraph_WriteDOT(t *testing.T) {
+	t.Parallel()
+
+	g := &ImplicationGraph{
+		PkgPath: "example.com/pkg",
+		Sites: []GraphSite{
+			{ID: 0, Site: "Param 0: 'x' of Function f", Position: "f.go:1:2", Nilability: "NILABLE", Reason: `"nil" literal`},
+			{ID: 1, Site: "Result 0 of Function f", Position: "f.go:3:4"},
+		},
+		Edges: []GraphEdge{
+			{From: 0, To: 1, Position: "f.go:5:6", Producer: `read by "x"`, Consumer: `returned \ f`},
+		},
+	}
+	var b strings.Builder
+	require.NoError(t, g.WriteDOT(&b))
+	require.Equal(t, `digraph "example.com/pkg" {
+	node [shape=box];
+	0 [label="Param 0: 'x' of Function f\nf.go:1:2", style=filled, fillcolor=salmon, tooltip="\"nil\" literal"];
+	1 [label="Result 0 of Function f\nf.go:3:4"];
+	0 -> 1 [label="f.go:5:6\nread by \"x\"\nreturned \\ f"];
+}
+`, b.String())
+}
@@ -90,6 +90,18 @@ func (i *InferredMap) Len() int {
 	return len(i.mapping.Pairs)
 }
 
+// NumImplications returns the number of implications (i.e., the edges of the implication graph)
+// between the undetermined sites currently stored in the map.
+func (i *InferredMap) NumImplications() int {
+	n := 0
+	for _, p := range i.mapping.Pairs {
+		if val, ok := p.Value.(*UndeterminedVal); ok {
+			n += len(val.Implicates.Pairs)
+		}
+	}
+	return n
+}
+
 // OrderedRange calls f sequentially for each annotation site and inferred value present in the map
 // in insertion order. If f returns false, range stops the iteration.
 func (i *InferredMap) OrderedRange(f func(primitiveSite, InferredVal) bool) {
@@ -103,10 +115,11 @@ func (i *InferredMap) OrderedRange(f func(primitiveSite, InferredVal) bool) {
 // Export only encodes new information not already present in the upstream maps, and it does not
 // encode all (in the go sense; i.e. capitalized) annotation sites (See chooseSitesToExport).
 // This ensures that only _incremental_ information is exported by this package and plays a _vital_
-// role in minimizing build output.
-func (i *InferredMap) Export(pass *analysis.Pass) {
+// role in minimizing build output. The exported map is returned, or nil if nothing is exported.
+// nilable(result 0)
+func (i *InferredMap) Export(pass *analysis.Pass) *InferredMap {
 	if len(i.mapping.Pairs) == 0 {
-		return
+		return nil
 	}
 
 	// First create a new map containing only the sites and their inferred values that we would
@@ -135,7 +148,9 @@ func (i *InferredMap) Export(pass *analysis.Pass) {
 		m := newInferredMap(nil /* primitive */)
 		m.mapping = exported
 		pass.ExportPackageFact(m)
+		return m
 	}
+	return nil
 }
 
 // GobEncode encodes the inferred map via gob encoding.
@@ -277,6 +292,21 @@ func (i *InferredMap) CheckFuncCallSiteRetAnn(key *annotation.CallSiteRetAnnotat
 	return i.checkAnnotationKey(key)
 }
 
+// CheckInferred returns the nilability of the shallow or deep site of the key provided, and
+// false if the site is not determined. Different from the methods above, the shallow and deep
+// sites are checked independently.
+func (i *InferredMap) CheckInferred(key annotation.Key, isDeep bool) (bool, bool) {
+	val, ok := i.mapping.Load(i.primitive.site(key, isDeep))
+	if !ok {
+		return false, false
+	}
+	determined, ok := val.(*DeterminedVal)
+	if !ok {
+		return false, false
+	}
+	return determined.Bool.Val(), true
+}
+
 func (i *InferredMap) checkAnnotationKey(key annotation.Key) (annotation.Val, bool) {
 	shallowKey := i.primitive.site(key, false)
 	deepKey := i.primitive.site(key, true)
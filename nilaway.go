@@ -19,6 +19,7 @@ package nilaway
 import (
 	"go.uber.org/nilaway/accumulation"
 	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/diagnostic"
 	"go.uber.org/nilaway/util"
 	"golang.org/x/tools/go/analysis"
 )
@@ -39,12 +40,13 @@ var Analyzer = &analysis.Analyzer{
 // nilable(result 0)
 func run(pass *analysis.Pass) (interface{}, error) {
 	conf := pass.ResultOf[config.Analyzer].(*config.Config)
-	deferredErrors := pass.ResultOf[accumulation.Analyzer].([]analysis.Diagnostic)
+	deferredErrors := pass.ResultOf[accumulation.Analyzer].([]diagnostic.Diagnostic)
 	for _, e := range deferredErrors {
+		d := e.Diagnostic
 		if conf.PrettyPrint {
-			e.Message = util.PrettyPrintErrorMessage(e.Message)
+			d.Message = util.PrettyPrintErrorMessage(d.Message)
 		}
-		pass.Report(e)
+		pass.Report(d)
 	}
 
 	return nil, nil
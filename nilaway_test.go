@@ -15,13 +15,16 @@
 package nilaway
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"go.uber.org/nilaway/config"
+	"go.uber.org/nilaway/inference"
 	"golang.org/x/tools/go/analysis/analysistest"
 )
 
@@ -162,6 +165,27 @@ func TestLoopFlow(t *testing.T) {
 	analysistest.Run(t, testdata, Analyzer, "go.uber.org/loopflow")
 }
 
+func TestGoroutine(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/goroutine")
+}
+
+func TestNoReturn(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/noreturn")
+}
+
+func TestTypeAssertion(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/typeassertion")
+}
+
 func TestMethodImplementation(t *testing.T) {
 	t.Parallel()
 
@@ -205,11 +229,18 @@ func TestGenerics(t *testing.T) {
 	analysistest.Run(t, testdata, Analyzer, "go.uber.org/generics")
 }
 
-func TestFunctionContracts(t *testing.T) {
-	t.Parallel()
+func TestFunctionContracts(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to enable the experimental
+	// contract inference, which the cross-package and method contracts are tested with as well.
+	err := config.Analyzer.Flags.Set(config.ExperimentalContractInferenceFlag, "true")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ExperimentalContractInferenceFlag, "false")
+		require.NoError(t, err)
+	}()
 
 	testdata := analysistest.TestData()
-	analysistest.Run(t, testdata, Analyzer, "go.uber.org/functioncontracts", "go.uber.org/functioncontracts/inference")
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/functioncontracts", "go.uber.org/functioncontracts/inference", "go.uber.org/functioncontracts/crosspackage", "go.uber.org/functioncontracts/methods")
 }
 
 func TestConstants(t *testing.T) {
@@ -254,6 +285,281 @@ func TestAnonymousFunction(t *testing.T) { //nolint:paralleltest
 	analysistest.Run(t, testdata, Analyzer, "go.uber.org/anonymousfunction")
 }
 
+func TestDeferStmt(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to enable the
+	// experimental support for anonymous function to test the deferred function literals.
+	err := config.Analyzer.Flags.Set(config.ExperimentalAnonymousFunctionFlag, "true")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ExperimentalAnonymousFunctionFlag, "false")
+		require.NoError(t, err)
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/deferstmt")
+}
+
+func TestDeferStmtDefaultMode(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/deferstmt/defaultmode")
+}
+
+func TestSuppression(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to enable the reporting
+	// of unused suppression directives to test this feature.
+	err := config.Analyzer.Flags.Set(config.ReportUnusedIgnoresFlag, "true")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ReportUnusedIgnoresFlag, "false")
+		require.NoError(t, err)
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/suppression")
+}
+
+func TestSkippedFuncs(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the size
+	// limits of the functions and enable the reporting of the skipped functions.
+	for flagName, value := range map[string]string{
+		config.MaxFuncBlocksFlag:      "8",
+		config.ReportSkippedFuncsFlag: "true",
+	} {
+		err := config.Analyzer.Flags.Set(flagName, value)
+		require.NoError(t, err)
+	}
+	defer func() {
+		for flagName, value := range map[string]string{
+			config.MaxFuncBlocksFlag:      "0",
+			config.ReportSkippedFuncsFlag: "false",
+		} {
+			err := config.Analyzer.Flags.Set(flagName, value)
+			require.NoError(t, err)
+		}
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/skippedfuncs")
+}
+
+func TestAnalysisBudget(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the analysis
+	// budget of the functions.
+	err := config.Analyzer.Flags.Set(config.MaxBackpropRoundsFlag, "3")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.MaxBackpropRoundsFlag, "0")
+		require.NoError(t, err)
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/analysisbudget")
+}
+
+func TestSuggestedFix(t *testing.T) {
+	t.Parallel()
+
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "go.uber.org/suggestedfix")
+}
+
+func TestDefaultNilability(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the default
+	// nilable and default nonnil types.
+	for flagName, value := range map[string]string{
+		config.DefaultNilableTypesFlag: "*go.uber.org/defaultnilability.Rows,go.uber.org/defaultnilability.Option",
+		config.DefaultNonnilTypesFlag:  "go.uber.org/defaultnilability.IDs,go.uber.org/defaultnilability.Names",
+	} {
+		err := config.Analyzer.Flags.Set(flagName, value)
+		require.NoError(t, err)
+	}
+	defer func() {
+		for _, flagName := range []string{config.DefaultNilableTypesFlag, config.DefaultNonnilTypesFlag} {
+			err := config.Analyzer.Flags.Set(flagName, "")
+			require.NoError(t, err)
+		}
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/defaultnilability")
+}
+
+func TestUserTrustedFuncs(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the config
+	// file that declares the trusted functions.
+	testdata := analysistest.TestData()
+	err := config.Analyzer.Flags.Set(config.ConfigFlag, filepath.Join(testdata, "src", "go.uber.org", "usertrustedfuncs", "nilaway.yaml"))
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ConfigFlag, "")
+		require.NoError(t, err)
+	}()
+
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/usertrustedfuncs")
+}
+
+func TestAnnotationStubs(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the config
+	// file that specifies the annotation stubs.
+	testdata := analysistest.TestData()
+	err := config.Analyzer.Flags.Set(config.ConfigFlag, filepath.Join(testdata, "src", "go.uber.org", "annotationstubs", "nilaway.yaml"))
+	require.NoError(t, err)
+	// The exclude list set for all tests (see TestMain) takes precedence over the one in the
+	// config file, so we clear it such that the annotated package is really excluded.
+	err = config.Analyzer.Flags.Set(config.ExcludePkgsFlag, "")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ConfigFlag, "")
+		require.NoError(t, err)
+		err = config.Analyzer.Flags.Set(config.ExcludePkgsFlag, "ignoredpkg1,ignoredpkg2")
+		require.NoError(t, err)
+	}()
+
+	analysistest.Run(t, testdata, Analyzer, "go.uber.org/annotationstubs", "go.uber.org/annotationstubs/inference")
+}
+
+func TestAnnotate(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to enable the annotate
+	// mode.
+	err := config.Analyzer.Flags.Set(config.AnnotateFlag, "true")
+	require.NoError(t, err)
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.AnnotateFlag, "false")
+		require.NoError(t, err)
+	}()
+
+	testdata := analysistest.TestData()
+	analysistest.RunWithSuggestedFixes(t, testdata, Analyzer, "go.uber.org/annotate")
+}
+
+func TestExplain(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the site to
+	// explain.
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.ExplainFlag, "")
+		require.NoError(t, err)
+	}()
+
+	testdata := analysistest.TestData()
+	for pkg, query := range map[string]string{
+		"go.uber.org/explain/nilable":      "go.uber.org/explain/nilable.pass:p",
+		"go.uber.org/explain/nonnil":       "go.uber.org/explain/nonnil.T.f",
+		"go.uber.org/explain/undetermined": "go.uber.org/explain/undetermined.link:result 0",
+	} {
+		err := config.Analyzer.Flags.Set(config.ExplainFlag, query)
+		require.NoError(t, err)
+		analysistest.Run(t, testdata, Analyzer, pkg)
+	}
+}
+
+func TestDumpImplicationGraph(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the directory
+	// to dump the implication graphs to.
+	defer func() {
+		for _, name := range [...]string{config.ImplicationGraphDirFlag, config.ImplicationGraphFuncFlag} {
+			err := config.Analyzer.Flags.Set(name, "")
+			require.NoError(t, err)
+		}
+	}()
+
+	// readGraph runs the analysis with the function to restrict the graph to, and returns the
+	// dumped graph mapping the sites to their nilabilities, along with the edges between them.
+	readGraph := func(funcName string) (map[string]string, [][2]string) {
+		dir := t.TempDir()
+		require.NoError(t, config.Analyzer.Flags.Set(config.ImplicationGraphDirFlag, dir))
+		require.NoError(t, config.Analyzer.Flags.Set(config.ImplicationGraphFuncFlag, funcName))
+		analysistest.Run(t, analysistest.TestData(), Analyzer, "go.uber.org/implicationgraph")
+
+		path := filepath.Join(dir, "go.uber.org", "implicationgraph")
+		dot, err := os.ReadFile(path + ".dot")
+		require.NoError(t, err)
+		require.Contains(t, string(dot), `digraph "go.uber.org/implicationgraph" {`)
+
+		content, err := os.ReadFile(path + ".json")
+		require.NoError(t, err)
+		var graph inference.ImplicationGraph
+		require.NoError(t, json.Unmarshal(content, &graph))
+		require.Equal(t, "go.uber.org/implicationgraph", graph.PkgPath)
+		sites := make(map[string]string)
+		for _, s := range graph.Sites {
+			sites[s.Site] = s.Nilability
+		}
+		edges := make([][2]string, 0, len(graph.Edges))
+		for _, e := range graph.Edges {
+			edges = append(edges, [2]string{graph.Sites[e.From].Site, graph.Sites[e.To].Site})
+		}
+		return sites, edges
+	}
+
+	linkSites := map[string]string{
+		"Param 0: 'x' of Function link":      "",
+		"Deep Param 0: 'x' of Function link": "",
+		"Result 0 of Function link":          "",
+		"Deep Result 0 of Function link":     "",
+	}
+	linkEdges := [][2]string{
+		{"Param 0: 'x' of Function link", "Result 0 of Function link"},
+		{"Deep Param 0: 'x' of Function link", "Deep Result 0 of Function link"},
+	}
+
+	// The graph of the entire package contains the determined sites as well.
+	sites, edges := readGraph("")
+	expected := map[string]string{
+		"Param 0: 'y' of Function deref": "NONNIL",
+		"Result 0 of Function source":    "NILABLE",
+	}
+	for s, nilability := range linkSites {
+		expected[s] = nilability
+	}
+	require.Equal(t, expected, sites)
+	require.ElementsMatch(t, linkEdges, edges)
+
+	sites, edges = readGraph("go.uber.org/implicationgraph.link")
+	require.Equal(t, linkSites, sites)
+	require.ElementsMatch(t, linkEdges, edges)
+}
+
+func TestStats(t *testing.T) { //nolint:paralleltest
+	// We specifically do not set this test to be parallel since we need to configure the directory
+	// to write the performance statistics to.
+	dir := t.TempDir()
+	require.NoError(t, config.Analyzer.Flags.Set(config.StatsFlag, dir))
+	defer func() {
+		err := config.Analyzer.Flags.Set(config.StatsFlag, "")
+		require.NoError(t, err)
+	}()
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "go.uber.org/implicationgraph")
+
+	content, err := os.ReadFile(filepath.Join(dir, "go.uber.org", "implicationgraph.json"))
+	require.NoError(t, err)
+	var stats struct {
+		Package          string             `json:"package"`
+		DurationsMs      map[string]float64 `json:"durationsMs"`
+		FullTriggers     int                `json:"fullTriggers"`
+		ImplicationEdges int                `json:"implicationEdges"`
+		Funcs            []struct {
+			Name   string `json:"name"`
+			Rounds int    `json:"rounds"`
+		} `json:"funcs"`
+	}
+	require.NoError(t, json.Unmarshal(content, &stats))
+	require.Equal(t, "go.uber.org/implicationgraph", stats.Package)
+	for _, name := range [...]string{"annotation", "function", "affiliation", "global", "accumulation"} {
+		require.Contains(t, stats.DurationsMs, name)
+	}
+	require.Positive(t, stats.FullTriggers)
+	// The shallow and deep implications from the param to the result of `link`.
+	require.Equal(t, 2, stats.ImplicationEdges)
+	names := make([]string, 0, len(stats.Funcs))
+	for _, f := range stats.Funcs {
+		names = append(names, f.Name)
+		require.Positive(t, f.Rounds)
+	}
+	require.Equal(t, []string{"link", "deref", "source"}, names)
+}
+
 func TestPrettyPrint(t *testing.T) { //nolint:paralleltest
 	// We specifically do not set this test to be parallel such that this test is run separately
 	// from the parallel tests. This makes it possible to set the pretty-print flag to true for
@@ -0,0 +1,41 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This package tests that the functions exceeding the analysis budget (here 3 backpropagation
+// rounds) are abandoned and reported, while the rest of the package is still analyzed.
+package analysisbudget
+
+var dummy bool
+
+func retNil() *int {
+	return nil
+}
+
+func straightLine() int {
+	p := retNil()
+	return *p //want "dereferenced"
+}
+
+func loop() int { //want "Analysis incomplete for `loop`: exceeded the budget of 3 backpropagation rounds"
+	p := retNil()
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			if dummy {
+				p = new(int)
+			}
+		}
+	}
+	// Not reported since the function is abandoned.
+	return *p
+}
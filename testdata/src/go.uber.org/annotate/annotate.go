@@ -0,0 +1,63 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package annotate tests writing the inferred annotations back into the doc comments (see the
+// golden file for the expected results), where the hand-written annotations are left intact.
+package annotate
+
+type Node struct { //want "Inferred annotation for `Node`"
+	Next  *Node
+	Value *int
+}
+
+var Root *Node //want "Inferred annotation for `Root`"
+
+var Cache = map[string]*Node{} //want "Inferred annotation for `Cache`"
+
+// newNode creates a new node.
+func newNode() *Node { //want "Inferred annotation for `newNode`"
+	if Root == nil {
+		return nil
+	}
+	return &Node{Next: Root, Value: new(int)}
+}
+
+func value(n *Node) int { //want "Inferred annotation for `value`"
+	return *n.Value
+}
+
+func use() int {
+	n := newNode()
+	Cache["a"] = nil
+	return value(n) + *Cache["b"].Value
+}
+
+// nonnil(result 0)
+func find(key string) *Node { //want "Hand-written annotation `nonnil\\(result 0\\)` for `find` disagrees"
+	return nil
+}
+
+// The blank param cannot be annotated, and functions bar nilness.
+func walk(n *Node, _ *Node, f func(*Node)) { //want "Inferred annotation for `walk`"
+	f(n.Next)
+}
+
+var (
+	count  int
+	Global *Node //want "Inferred annotation for `Global`"
+)
+
+func setGlobal() {
+	Global = nil
+}
@@ -0,0 +1,60 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This package tests the annotation stub files (see stubs directory), which annotate the package
+`thirdparty` that is excluded from analysis.
+
+<nilaway no inference>
+*/
+package annotationstubs
+
+import "go.uber.org/annotationstubs/thirdparty"
+
+func testField(r *thirdparty.Request) int {
+	_ = r.URL.Path
+	return r.Body.N //want "accessed field `N`"
+}
+
+func testMethod(c *thirdparty.Client) string {
+	c.Do(nil) //want "passed as arg `req`"
+	r := c.Do(&thirdparty.Request{})
+	return r.URL.Path //want "accessed field `URL`"
+}
+
+// nonnil(rows)
+func testDeepType(rows thirdparty.Rows) *thirdparty.URL {
+	return rows[0].URL //want "accessed field `URL`"
+}
+
+func testGlobal() thirdparty.Client {
+	// The methods of the packages out of scope are assumed to handle nil receivers, so calling a
+	// method on the nilable `Default` is not reported since `thirdparty` is excluded.
+	thirdparty.Default.Do(&thirdparty.Request{})
+	return *thirdparty.Default //want "global variable `Default`"
+}
+
+func testFunc() string {
+	if thirdparty.Lookup("a").URL != nil { //want "accessed field `URL`"
+		return thirdparty.Parse(nil) //want "passed as arg `u`"
+	}
+	return ""
+}
+
+// The results of NewRequest and Current are annotated by the stub file contrary to their bodies, so
+// the cases below only pass if the stub file applies to the excluded package.
+func testStubOverridesBody() *thirdparty.URL {
+	_ = thirdparty.Current().URL
+	return thirdparty.NewRequest().URL //want "result 0 of `NewRequest\\(\\)`"
+}
@@ -0,0 +1,49 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package inference tests the annotation stub files with inference enabled, where the errors
+// caused by the nonnil annotations in the stubs are reported in this package instead of the
+// annotated (and excluded) package.
+package inference
+
+import "go.uber.org/annotationstubs/thirdparty"
+
+func testField(r *thirdparty.Request) int {
+	_ = r.URL.Path
+	return r.Body.N //want "accessed field `N`"
+}
+
+func testParam(c *thirdparty.Client) {
+	c.Do(nil) //want "passed as arg `req`"
+}
+
+func testResult() string {
+	r := thirdparty.Lookup("a")
+	return r.URL.Path //want "accessed field `URL`"
+}
+
+func testParamFlow() string {
+	var u *thirdparty.URL
+	return thirdparty.Parse(u) //want "passed as arg `u`"
+}
+
+func testMethodResult(c *thirdparty.Client) string {
+	r := c.Do(&thirdparty.Request{})
+	return r.URL.Path //want "accessed field `URL`"
+}
+
+func testGlobal() thirdparty.Client {
+	c := thirdparty.Default
+	return *c //want "global variable `Default`"
+}
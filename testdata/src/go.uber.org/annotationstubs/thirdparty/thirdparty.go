@@ -0,0 +1,56 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package thirdparty mimics a third-party package that cannot be annotated in source, which is
+// excluded from analysis and annotated by the stub file instead.
+package thirdparty
+
+type Request struct {
+	URL  *URL
+	Body *Body
+}
+
+type URL struct {
+	Path string
+}
+
+type Body struct {
+	N int
+}
+
+type Client struct{}
+
+func (*Client) Do(req *Request) *Request {
+	return req
+}
+
+type Rows []*Request
+
+var Default *Client
+
+func Lookup(key string) *Request {
+	return nil
+}
+
+func Parse(u *URL) string {
+	return u.Path
+}
+
+func NewRequest() *Request {
+	return &Request{}
+}
+
+func Current() *Request {
+	return nil
+}
@@ -0,0 +1,42 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anonymousfunction
+
+// Test the contracts of the function literals, which are written right above them.
+
+func testContracts() {
+	// contract(nonnil -> nonnil)
+	clone := func(x *int) *int {
+		if x == nil {
+			return nil
+		}
+		y := *x
+		return &y
+	}
+	n := 1
+	print(*clone(&n)) // No "nilable value dereferenced" wanted
+	var p *int
+	print(*clone(p)) //want "dereferenced"
+
+	// contract(true -> nonnil)
+	get := func(must bool) *int {
+		if must {
+			return new(int)
+		}
+		return nil
+	}
+	print(*get(true))  // No "nilable value dereferenced" wanted
+	print(*get(false)) //want "dereferenced"
+}
@@ -0,0 +1,44 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anonymousfunction
+
+// Test the function literals spawned as goroutines via the variables they are assigned to, and the
+// ones called after the captured variables are reassigned.
+
+func testGoroutineViaVar() {
+	x := new(int)
+	f := func() {
+		print(*x) //want "literal `nil` passed as arg `x` to .* via the assignment"
+	}
+	go f()
+	x = nil
+}
+
+func testGoroutineViaVarNotReassigned() {
+	x := new(int)
+	f := func() {
+		print(*x)
+	}
+	go f()
+}
+
+func testCalledAfterReassigned() {
+	x := new(int)
+	f := func() {
+		print(*x) //want "literal `nil` passed as arg `x`"
+	}
+	x = nil
+	f()
+}
@@ -586,24 +586,24 @@ func plainReflCheck(ch chan any) any {
 	return ch //want "returned"
 }
 
-// BELOW TESTS CHECK SHALLOW NILABILITY OF CHANNELS :: SEND AND RECEIVE ON NIL CHANNELS
+// BELOW TESTS CHECK SHALLOW NILABILITY OF CHANNELS :: SEND AND RECEIVE ON NIL CHANNELS (ONLY SENDS ARE REPORTED)
 var nilChanGlobal chan string
 var nonnilChanGlobal = make(chan string)
 
 func testSendToGlobalChan() {
-	nilChanGlobal <- "xyz"
+	nilChanGlobal <- "xyz" //want "sent to"
 	nonnilChanGlobal <- "xyz"
 }
 
 // nonnil(nonnilChanParam)
 func testSendToParamChan(nilChanParam chan string, nonnilChanParam chan string) {
-	nilChanParam <- "xyz"
+	nilChanParam <- "xyz" //want "sent to"
 	nonnilChanParam <- "xyz"
 }
 
 func testSendToLocalChan() {
 	var nilChanLocal chan string
-	nilChanLocal <- "xyz"
+	nilChanLocal <- "xyz" //want "sent to"
 
 	var nonnilChanLocal = make(chan string)
 	nonnilChanLocal <- "xyz"
@@ -622,7 +622,7 @@ func testRecvFromParamChan(nilChanParam chan string, nonnilChanParam chan string
 
 func testRecvFromLocalChan() {
 	var nilChanLocal chan string
-	nilChanLocal <- "xyz"
+	nilChanLocal <- "xyz" //want "sent to"
 	v1 := <-nilChanLocal
 
 	var nonnilChanLocal = make(chan string)
@@ -644,14 +644,14 @@ func retNonNilChan() chan string {
 
 func testSendRecvFuncRet() {
 	nilChanLocal := retNilChan()
-	nilChanLocal <- "xyz"
+	nilChanLocal <- "xyz" //want "sent to"
 	v1 := <-nilChanLocal
 
 	nonnilChanLocal := retNonNilChan()
 	nonnilChanLocal <- "xyz"
 	v2 := <-nonnilChanLocal
 
-	nilChanLocal <- <-nonnilChanGlobal
+	nilChanLocal <- <-nonnilChanGlobal //want "sent to"
 	nonnilChanLocal <- <-nonnilChanGlobal
 
 	func(...any) {}(v1, v2)
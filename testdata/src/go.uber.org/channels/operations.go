@@ -0,0 +1,75 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package channels
+
+// THESE TESTS CHECK THE NILABILITY OF THE CHANNELS CLOSED, AND SENT TO AND RECEIVED FROM IN SELECT STATEMENTS
+
+var nilChanToClose chan int
+
+// nonnil(nonnilChan)
+func testClose(nilableChan chan int, nonnilChan chan int) {
+	switch 0 {
+	case 1:
+		close(nilableChan) //want "closed"
+	case 2:
+		close(nonnilChan)
+	case 3:
+		close(nilChanToClose) //want "closed"
+	case 4:
+		var nilChanLocal chan int
+		close(nilChanLocal) //want "closed"
+	case 5:
+		ch := make(chan int)
+		close(ch)
+	case 6:
+		if nilableChan != nil {
+			close(nilableChan)
+		}
+	}
+}
+
+// A case of a select statement on a nil channel is never chosen, so the channels in select
+// statements can be nil (e.g., to disable some of the cases), and nothing is reported.
+// nonnil(nonnilChan)
+func testSelect(nilableChan chan *int, nonnilChan chan *int) *int {
+	i := 0
+	select {
+	case nilableChan <- &i:
+	case nonnilChan <- &i:
+	case v := <-nilableChan:
+		return v
+	}
+
+	var disabled chan *int
+	select {
+	case disabled <- &i:
+	default:
+		disabled <- &i //want "sent to"
+	}
+	return &i
+}
+
+// A receive in the `ok` form gives the zero value if the channel is closed, which is guarded by
+// the `ok` in select statements as well.
+// nonnil(ch, <-ch)
+func testSelectOkRecv(ch chan *int) *int {
+	select {
+	case v, ok := <-ch:
+		if ok {
+			return v
+		}
+		return v //want "returned"
+	}
+}
@@ -0,0 +1,87 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+This package tests the user-configurable default nilable and default nonnil types, where the test
+configures `*Rows` and `Option` as default nilable, and `IDs` and `Names` as default nonnil.
+
+<nilaway no inference>
+*/
+package defaultnilability
+
+type Rows struct {
+	n int
+}
+
+type Option[T any] interface {
+	Get() T
+}
+
+type IDs []int
+
+type Names map[int]string
+
+type Plain []int
+
+func rowsParam(r *Rows) int {
+	return r.n //want "accessed field `n`"
+}
+
+// nonnil(r)
+func rowsParamAnnotated(r *Rows) int {
+	return r.n
+}
+
+func optionParam(o Option[int]) int {
+	return o.Get() //want "called `Get\\(\\)`"
+}
+
+func retRows() *Rows {
+	return nil
+}
+
+func retOption() Option[string] {
+	return nil
+}
+
+func idsParam(ids IDs) int {
+	return ids[0]
+}
+
+func namesParam(names Names) string {
+	names[0] = "foo"
+	return names[1]
+}
+
+func plainParam(p Plain) int {
+	return p[0] //want "sliced into"
+}
+
+func retIDs() IDs {
+	return nil //want "returned"
+}
+
+func retPlain() Plain {
+	return nil
+}
+
+// nonnil(ids)
+func deepIDs(ids []IDs) int {
+	return ids[0][0]
+}
+
+// nonnil(p)
+func deepPlain(p [][]int) int {
+	return p[0][0] //want "sliced into"
+}
@@ -0,0 +1,75 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package defaultmode tests the deferred calls with the experimental anonymous function support
+// disabled, in which case the bodies of the deferred function literals are analyzed as well,
+// including the ones deferred via the variables they are assigned to.
+package defaultmode
+
+type Conn struct {
+	closed bool
+}
+
+func (c *Conn) Close() {
+	c.closed = true //want "unassigned variable `c` used as receiver to call `Close\\(\\)`"
+}
+
+func testMethodOnNilReceiver() {
+	var c *Conn
+	defer c.Close()
+}
+
+func testClosureNilAtExit() {
+	i := 1
+	p := &i
+	defer func() {
+		print(*p) //want "literal `nil` passed as arg `p`"
+	}()
+	p = nil
+}
+
+func testClosureNonnilAtExit() {
+	var p *int
+	defer func() {
+		print(*p)
+	}()
+	i := 1
+	p = &i
+}
+
+func testNamedResultNilAtExit() (p *int) {
+	defer func() {
+		print(*p) //want "dereferenced"
+	}()
+	return nil
+}
+
+func testConditionalDefer(b bool) {
+	var p *int
+	if b {
+		defer func() {
+			print(*p) //want "unassigned variable `p` passed as arg `p`"
+		}()
+	}
+	i := 1
+	p = &i
+}
+
+func testClosureViaVar() {
+	var p *int
+	f := func() {
+		print(*p) //want "unassigned variable `p` passed as arg `p`"
+	}
+	defer f()
+}
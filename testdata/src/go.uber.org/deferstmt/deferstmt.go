@@ -0,0 +1,273 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+These tests check that the deferred calls are modeled: the receivers and the arguments of the
+deferred calls are evaluated at the defer statements, while the deferred function literals are
+called (hence read the captured variables) at the function exits.
+
+Only the exits via return statements (or the end of the function body) are modeled: the panicking
+exits, where the deferred calls run as well (e.g., to recover), are out of scope. Moreover, the
+assignments to the named results in the deferred function literals are not modeled.
+*/
+package deferstmt
+
+type recoveredError struct {
+	r any
+}
+
+func (*recoveredError) Error() string {
+	return "recovered"
+}
+
+type Conn struct {
+	closed bool
+}
+
+// The nil receivers flowing from the deferred calls below are reported here.
+func (c *Conn) Close() {
+	c.closed = true //want "unassigned variable `c` used as receiver to call `Close\\(\\)`" "literal `nil` passed as arg `c`" "unassigned variable `c2` passed as arg `c2`"
+}
+
+func open() (*Conn, error) {
+	return &Conn{}, nil
+}
+
+func use(p *int) {
+	print(*p) //want "unassigned variable `p` passed as arg `p` to `use\\(\\)`"
+}
+
+func testMethodOnNilReceiver() {
+	var c *Conn
+	defer c.Close()
+}
+
+func testReceiverEvaluatedAtDefer() {
+	c := &Conn{}
+	defer c.Close()
+	c = nil
+	_ = c
+}
+
+func testArgEvaluatedAtDefer() {
+	var p *int
+	defer use(p)
+	i := 1
+	p = &i
+	print(*p)
+}
+
+func testArgEvaluatedAtDeferSafe() {
+	i := 1
+	p := &i
+	defer use(p)
+	p = nil
+	_ = p
+}
+
+func testClosureNilAtExit() {
+	c := &Conn{}
+	defer func() {
+		c.Close()
+	}()
+	c = nil
+}
+
+func testClosureNonnilAtExit() {
+	var c *Conn
+	defer func() {
+		c.Close()
+	}()
+	c = &Conn{}
+}
+
+func testClosureAssignedToVar() {
+	var p *int
+	f := func() {
+		print(*p) //want "dereferenced"
+	}
+	defer f()
+}
+
+func testEarlyReturnBeforeDefer() error {
+	c, err := open()
+	if err != nil {
+		// The deferred call below is not executed when returning here.
+		return err
+	}
+	defer func() {
+		c.Close()
+	}()
+	return nil
+}
+
+func testConditionalDefer(b bool) {
+	var c *Conn
+	if b {
+		c = &Conn{}
+		defer func() {
+			c.Close()
+		}()
+	}
+}
+
+// The calls deferred conditionally are not known to be executed at the function exits, so the
+// captured variables are read at the defer statements instead.
+func testConditionalDeferNilAtDefer(b bool) {
+	var p *int
+	if b {
+		defer func() {
+			print(*p) //want "dereferenced"
+		}()
+	}
+	i := 1
+	p = &i
+}
+
+func testConditionalDeferNotExecutedOnOtherPath(p *int) {
+	if p != nil {
+		defer func() {
+			print(*p)
+		}()
+	}
+}
+
+func testDeferInLoop(n int) {
+	for i := 0; i < n; i++ {
+		var p *int
+		if i > 0 {
+			p = &i
+		}
+		defer func() {
+			print(*p) //want "dereferenced"
+		}()
+	}
+}
+
+func testDeferInLoopSafe(n int) {
+	for i := 0; i < n; i++ {
+		p := &i
+		defer func() {
+			print(*p)
+		}()
+	}
+}
+
+func testDeferInLoopWithReturn(ps []*int) {
+	for _, p := range ps {
+		if p == nil {
+			return
+		}
+		defer func() {
+			print(*p)
+		}()
+	}
+}
+
+func testNamedResultNilAtExit() (p *int) {
+	defer func() {
+		print(*p) //want "dereferenced"
+	}()
+	return nil
+}
+
+func testNamedResultNonnilAtExit() (p *int) {
+	defer func() {
+		print(*p)
+	}()
+	i := 1
+	return &i
+}
+
+func testMultipleDefers() {
+	var c1, c2 *Conn
+	defer func() {
+		c1.Close()
+	}()
+	defer func() {
+		c2.Close()
+	}()
+	c1 = &Conn{}
+}
+
+func testRecover() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &recoveredError{r: r}
+		}
+	}()
+	c, err := open()
+	if err != nil {
+		return err
+	}
+	c.Close()
+	return &recoveredError{}
+}
+
+func mayPanic() {}
+
+// The deferred function literal is called at the normal exit as well, where `p` is nil.
+func testRecoverNilAtExit() {
+	var p *int
+	defer func() {
+		if recover() != nil {
+			print(*p) //want "dereferenced"
+		}
+	}()
+	mayPanic()
+}
+
+// `p` is nil when `mayPanic` panics, but the panicking exits are out of scope.
+func testRecoverNilAtPanickingExit() {
+	var p *int
+	defer func() {
+		if recover() != nil {
+			print(*p)
+		}
+	}()
+	mayPanic()
+	i := 1
+	p = &i
+}
+
+// The named result is still nil at the normal exit, since it is only set on recovering.
+func testRecoverSetsNamedResult() (p *int) {
+	defer func() {
+		if recover() != nil {
+			i := 1
+			p = &i
+		}
+	}()
+	mayPanic()
+	return nil
+}
+
+// The named result is nil when recovering from a panic in `mayPanic`, but the panicking exits are
+// out of scope (and so are the assignments to the named results in the deferred function
+// literals).
+func testRecoverSetsNamedResultNil() (p *int) {
+	defer func() {
+		if recover() != nil {
+			p = nil
+		}
+	}()
+	mayPanic()
+	i := 1
+	return &i
+}
+
+func useRecoverSetsNamedResult() {
+	print(*testRecoverSetsNamedResult()) //want "dereferenced"
+	print(*testRecoverSetsNamedResultNil())
+}
@@ -104,7 +104,7 @@ func test9(m map[int]*int) {
 // nilable(nilableChan) nonnil(nonnilDeeplyNonnilChan, <-nonnilDeeplyNonnilChan)
 func test10(nilableChan chan *int, nonnilDeeplyNonnilChan chan *int) {
 	x := 1
-	nilableChan <- &x
+	nilableChan <- &x //want "`nilableChan` sent to"
 	// Sending nilable values to nonnil and deeply nonnil channels is not OK.
 	var y *int
 	nonnilDeeplyNonnilChan <- y //want "`y` assigned deeply into parameter arg `nonnilDeeplyNonnilChan`"
@@ -0,0 +1,32 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nilable tests explaining why a param is inferred nilable, where the explanation follows
+// the nil flow from the nil source to the param.
+package nilable
+
+func source(b bool) *int {
+	if b {
+		return nil
+	}
+	return new(int)
+}
+
+func pass(p *int) *int { //want "Param 0: 'p' of Function pass is determined NILABLE:\n.*literal `nil`.* returned from `source\\(\\)`.*\n.*passed as arg `p` to `pass\\(\\)`"
+	return p
+}
+
+func use() *int {
+	return pass(source(true))
+}
@@ -0,0 +1,29 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nonnil tests explaining why a field is inferred nonnil, where the explanation follows
+// the flow from the field to the dereference.
+package nonnil
+
+type T struct {
+	f *int //want "Field f is determined NONNIL:\n.*field `f` passed as arg `p` to `deref\\(\\)`.*\n.*function parameter `p`.* dereferenced"
+}
+
+func deref(p *int) int {
+	return *p
+}
+
+func use(t *T) int {
+	return deref(t.f)
+}
@@ -0,0 +1,21 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package undetermined tests explaining an undetermined result, where the explanation lists the
+// implications to and from the result.
+package undetermined
+
+func link(p *int) *int { //want "Result 0 of Function link is still undetermined\nit is NILABLE if any of the following is NILABLE:\n.*Param 0: 'p' of Function link"
+	return p
+}
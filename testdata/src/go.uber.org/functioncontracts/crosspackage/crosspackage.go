@@ -0,0 +1,54 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crosspackage tests applying the contracts of the functions from the upstream package at
+// the call sites.
+package crosspackage
+
+import "go.uber.org/functioncontracts/crosspackage/upstream"
+
+func useWrap1() {
+	n := 1
+	print(upstream.Wrap(&n).V) // No "nilable value accessed" wanted
+}
+
+func useWrap2() {
+	print(upstream.Wrap(nil).V) // want "accessed field `V`"
+}
+
+func useIdentity1() {
+	n := 1
+	print(*upstream.Identity(&n)) // No "nilable value dereferenced" wanted
+}
+
+func useIdentity2() {
+	print(*upstream.Identity(nil)) // want "dereferenced"
+}
+
+func useUse() {
+	// The contract does not hide the requirement of the function on the param.
+	upstream.Use(nil) // want "literal `nil` passed as arg `x` to `Use\\(\\)`"
+}
+
+func useUnwrap(b *upstream.Box) {
+	n := 1
+	print(*b.Unwrap(&n))  // No "nilable value dereferenced" wanted
+	print(*b.Unwrap(nil)) // want "dereferenced"
+}
+
+func useUnwrapMethodExpr(b *upstream.Box) {
+	n := 1
+	print(*(*upstream.Box).Unwrap(b, &n))  // No "nilable value dereferenced" wanted
+	print(*(*upstream.Box).Unwrap(b, nil)) // want "dereferenced"
+}
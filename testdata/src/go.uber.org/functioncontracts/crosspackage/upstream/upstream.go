@@ -0,0 +1,53 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package upstream publishes the contracts of its functions for the downstream package
+// `crosspackage`.
+package upstream
+
+// Box boxes a value.
+type Box struct {
+	V *int
+}
+
+// Wrap boxes the value if it is not nil.
+// contract(nonnil -> nonnil)
+func Wrap(x *int) *Box {
+	if x == nil {
+		return nil
+	}
+	return &Box{V: x}
+}
+
+// Identity returns the value as is, which has the inferred contract `contract(nonnil -> nonnil)`.
+func Identity(x *int) *int {
+	return x
+}
+
+// Use dereferences the value and returns it.
+// nonnil(x)
+// contract(nonnil -> nonnil)
+func Use(x *int) *int {
+	print(*x)
+	return x
+}
+
+// Unwrap returns the value in the box.
+// contract(nonnil -> nonnil)
+func (b *Box) Unwrap(x *int) *int {
+	if b.V != nil {
+		return b.V
+	}
+	return x
+}
@@ -0,0 +1,95 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+import "errors"
+
+// Test the contracts with `nil` and constant bool values, and the error-aware contracts, which are
+// evaluated at the call sites.
+
+// contract(nil -> nil)
+func copyOf(x *int) *int {
+	if x == nil {
+		return nil
+	}
+	y := *x
+	return &y
+}
+
+func useCopyOf() {
+	print(*copyOf(nil)) //want "determined to be nil by `contract\\(nil -> nil\\)`"
+}
+
+// contract(true -> nonnil)
+func lookup(must bool) *int {
+	if must {
+		return new(int)
+	}
+	return nil
+}
+
+func useLookup() {
+	print(*lookup(true))  // No "nilable value dereferenced" wanted
+	print(*lookup(false)) //want "dereferenced"
+}
+
+// contract(_ -> nonnil, nil)
+func parse(s string) (*int, error) {
+	if s == "" {
+		return nil, errors.New("empty")
+	}
+	v := len(s)
+	return &v, nil
+}
+
+func useParse() {
+	v, err := parse("x")
+	if err != nil {
+		return
+	}
+	print(*v) // No "nilable value dereferenced" wanted
+
+	w, _ := parse("y")
+	print(*w) //want "dereferenced"
+}
+
+// contract(_ -> nonnil, true)
+func find(s string) (*int, bool) {
+	if s == "" {
+		return nil, false
+	}
+	return new(int), true
+}
+
+func useFind() {
+	if v, ok := find("x"); ok {
+		print(*v) // No "nilable value dereferenced" wanted
+	}
+}
+
+/* want "Invalid function contract `contract\\(nonnil -> true\\)`: value \"true\" for result 0 of non-bool type" */ // contract(nonnil -> true)
+func invalidContract(x *int) *int {
+	return x
+}
+
+/* want "Invalid function contract: unknown value \"nilable\"" */ // contract(nilable -> nonnil)
+func unknownValue(x *int) *int {
+	return x
+}
+
+/* want "Malformed function contract" */ // contract(nonnil => nonnil)
+func malformedContract(x *int) *int {
+	return x
+}
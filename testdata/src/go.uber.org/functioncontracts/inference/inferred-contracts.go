@@ -0,0 +1,58 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inference
+
+type wrapper struct {
+	v *int
+}
+
+// Test the contract `contract(nonnil -> nonnil)` inferred from the function body, where nil is
+// returned only if the param is nil.
+func wrap(x *int) *wrapper {
+	if x == nil {
+		return nil
+	}
+	return &wrapper{v: x}
+}
+
+func useWrap1() {
+	n := 1
+	w := wrap(&n)
+	print(w.v) // No "nilable value accessed" wanted
+}
+
+func useWrap2() {
+	w := wrap(nil)
+	print(w.v) // want "accessed field `v`"
+}
+
+// Test the contract is inferred for a function returning the param via another function with an
+// inferred contract.
+func forward(x *int) *int {
+	return identity(x)
+}
+
+func identity(x *int) *int {
+	return x
+}
+
+func useForward1() {
+	n := 1
+	print(*forward(&n)) // No "nilable value dereferenced" wanted
+}
+
+func useForward2() {
+	print(*forward(nil)) // want "dereferenced"
+}
@@ -0,0 +1,107 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package methods tests the contracts of methods and interface methods.
+package methods
+
+type cloner struct{}
+
+// contract(nonnil -> nonnil)
+func (cloner) clone(x *int) *int {
+	if x == nil {
+		return nil
+	}
+	y := *x
+	return &y
+}
+
+// contract(true -> nonnil)
+func (cloner) get(must bool) *int {
+	if must {
+		return new(int)
+	}
+	return nil
+}
+
+func useMethods(c cloner) {
+	n := 1
+	print(*c.clone(&n)) // No "nilable value dereferenced" wanted
+	var p *int
+	print(*c.clone(p))   //want "dereferenced"
+	print(*c.get(true))  // No "nilable value dereferenced" wanted
+	print(*c.get(false)) //want "dereferenced"
+}
+
+func useMethodExprs(c cloner) {
+	n := 1
+	// The receiver is passed as the first argument of the method expressions, which is not the
+	// param in the contract.
+	print(*cloner.clone(c, &n)) // No "nilable value dereferenced" wanted
+	var p *int
+	print(*cloner.clone(c, p)) //want "dereferenced"
+}
+
+// Cloner declares the contracts of its methods, which must be redeclared by all the
+// implementations.
+type Cloner interface {
+	// contract(nonnil -> nonnil)
+	Clone(x *int) *int
+	// contract(true -> nonnil)
+	Get(must bool) *int
+}
+
+type goodCloner struct{}
+
+// Clone has the inferred contract `contract(nonnil -> nonnil)`.
+func (goodCloner) Clone(x *int) *int {
+	if x == nil {
+		return nil
+	}
+	y := *x
+	return &y
+}
+
+// contract(true -> nonnil)
+func (goodCloner) Get(must bool) *int {
+	if must {
+		return new(int)
+	}
+	return nil
+}
+
+// undeclaredCloner does not redeclare the contracts of Cloner. Clone violates its contract, while
+// Get satisfies its contract since it never returns nil, but both are reported since the contracts
+// are not checked against the bodies.
+type undeclaredCloner struct{}
+
+func (undeclaredCloner) Clone(x *int) *int { //want "Method `undeclaredCloner.Clone` does not redeclare `contract\\(nonnil -> nonnil\\)` of the interface method `Cloner.Clone`"
+	return nil
+}
+
+func (undeclaredCloner) Get(must bool) *int { //want "Method `undeclaredCloner.Get` does not redeclare `contract\\(true -> nonnil\\)` of the interface method `Cloner.Get`"
+	return new(int)
+}
+
+func newClones() []Cloner {
+	return []Cloner{goodCloner{}, undeclaredCloner{}}
+}
+
+func useInterface(c Cloner) {
+	n := 1
+	print(*c.Clone(&n)) // No "nilable value dereferenced" wanted
+	var p *int
+	print(*c.Clone(p))   //want "dereferenced"
+	print(*c.Get(true))  // No "nilable value dereferenced" wanted
+	print(*c.Get(false)) //want "dereferenced"
+}
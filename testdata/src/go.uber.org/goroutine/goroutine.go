@@ -0,0 +1,126 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package goroutine tests the nilability of the variables captured by the function literals
+// spawned as goroutines, which are analyzed even if the experimental anonymous function support is
+// disabled. Since a goroutine may read a captured variable at any time after it is spawned, the
+// values assigned to the variable afterward are observed by the goroutine as well.
+package goroutine
+
+type A struct {
+	f int
+}
+
+var dummy bool
+
+func newA() *A {
+	if dummy {
+		return nil
+	}
+	return &A{}
+}
+
+func testBodyAnalyzed() {
+	go func() {
+		var a *A
+		print(a.f) //want "unassigned variable `a` accessed field `f`"
+	}()
+}
+
+func testArgs() {
+	go func(a *A) {
+		print(a.f) //want "literal `nil` passed as arg `a`"
+	}(nil)
+}
+
+func testCapturedNil() {
+	var a *A
+	go func() {
+		print(a.f) //want "unassigned variable `a` passed as arg `a`"
+	}()
+}
+
+func testCheckedBeforeSpawn() {
+	a := newA()
+	if a != nil {
+		go func() {
+			print(a.f)
+		}()
+	}
+}
+
+func testReassignedAfterSpawn() {
+	a := newA()
+	if a != nil {
+		go func() {
+			print(a.f) //want "literal `nil` passed as arg `a` to .* via the assignment"
+		}()
+	}
+	a = nil
+	print(a == nil)
+}
+
+func testReassignedNonnilAfterSpawn() {
+	a := newA()
+	if a == nil {
+		return
+	}
+	go func() {
+		print(a.f)
+	}()
+	a = &A{}
+	print(a.f)
+}
+
+func testReassignedInLoop() {
+	var a *A
+	for i := 0; i < 3; i++ {
+		// The assignment precedes the go statement, but it may still be executed (in the next
+		// iteration) while the goroutine is running.
+		a = newA()
+		if a == nil {
+			continue
+		}
+		go func() {
+			print(a.f) //want "result 0 of `newA.*` passed as arg `a`"
+		}()
+	}
+}
+
+func testNotCaptured() {
+	a := &A{}
+	go func(a *A) {
+		print(a.f)
+	}(a)
+	a = nil
+	print(a == nil)
+}
+
+func testSpawnedViaVar() {
+	a := &A{}
+	f := func() {
+		print(a.f) //want "literal `nil` passed as arg `a` to .* via the assignment"
+	}
+	go f()
+	a = nil
+	print(a == nil)
+}
+
+func testSpawnedViaVarNotReassigned() {
+	a := &A{}
+	f := func() {
+		print(a.f)
+	}
+	go f()
+}
@@ -0,0 +1,102 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goroutine
+
+// The function literals called synchronously are analyzed as well. Unlike the goroutines, they
+// read the captured variables when they are called, so only the values assigned before the calls
+// are observed.
+
+func testSyncCallDirect() {
+	var a *A
+	func() {
+		print(a.f) //want "unassigned variable `a` passed as arg `a`"
+	}()
+}
+
+func testSyncCallCapturedNil() {
+	a := &A{}
+	f := func() {
+		print(a.f) //want "literal `nil` passed as arg `a`"
+	}
+	a = nil
+	f()
+}
+
+func testSyncCallAssignedAfterCall() {
+	a := &A{}
+	f := func() {
+		print(a.f)
+	}
+	f()
+	a = nil
+	_ = a
+}
+
+func testSyncCallCheckedBeforeCall() {
+	a := newA()
+	f := func() {
+		print(a.f)
+	}
+	if a != nil {
+		f()
+	}
+}
+
+func testSyncCallBodyAnalyzed() {
+	f := func() {
+		var a *A
+		print(a.f) //want "unassigned variable `a` accessed field `f`"
+	}
+	f()
+}
+
+func testSyncCallTypeSwitchVar(x any) {
+	switch a := x.(type) {
+	case *A:
+		f := func() {
+			print(a.f)
+		}
+		f()
+	case nil:
+		f := func() {
+			print(a.(*A).f) //want "literal `nil` passed as arg `a`"
+		}
+		f()
+	}
+}
+
+// The variables captured by the called function literals are passed at the call sites, hence they
+// are captured by the calling function literals as well.
+func testSyncCallFromFuncLit(b bool) int {
+	a := &A{}
+	f := func() int {
+		return a.f
+	}
+	g := func() int {
+		if b {
+			return f()
+		}
+		return 0
+	}
+
+	var c *A
+	h := func() int {
+		return c.f //want "unassigned variable `c` passed as arg `c`"
+	}
+	k := func() int {
+		return h()
+	}
+	return g() + f() + k()
+}
@@ -0,0 +1,43 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fatal provides the helpers that never return, which are inferred and exported as facts
+// to the downstream packages by the ctrlflow analysis, except for Fail that calls `testing.TB.Fatal`.
+package fatal
+
+import (
+	"os"
+	"testing"
+)
+
+// Exit exits the program after printing the message.
+func Exit(msg string) {
+	println(msg)
+	os.Exit(1)
+}
+
+// Fail fails the test with the message.
+func Fail(tb testing.TB, msg string) {
+	tb.Fatal(msg)
+}
+
+// Reporter reports the failures.
+type Reporter struct{}
+
+// Report panics with the message.
+func (r *Reporter) Report(msg string) {
+	for {
+		panic(msg)
+	}
+}
@@ -0,0 +1,164 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+These tests check that the branches ending in calls to the functions that never return (e.g.,
+`os.Exit`, `log.Fatal` and `t.Fatal`) are treated as dead, including the functions inferred by
+the ctrlflow analysis to never return in the current and the upstream packages. Note that the
+functions that never return only because they call the methods of `testing.TB` are not inferred.
+
+<nilaway no inference>
+*/
+package noreturn
+
+import (
+	"log"
+	"os"
+	"testing"
+
+	"go.uber.org/noreturn/fatal"
+)
+
+type A struct {
+	f int
+}
+
+// nilable(a)
+func testOsExit(a *A) int {
+	if a == nil {
+		os.Exit(1)
+	}
+	return a.f
+}
+
+// nilable(a)
+func testLogFatal(a *A, logger *log.Logger) int {
+	switch {
+	case a == nil && logger == nil:
+		log.Fatalf("nil a")
+	case a == nil:
+		logger.Fatalln("nil a")
+	}
+	return a.f
+}
+
+// nilable(a)
+func testLogPanic(a *A) int {
+	if a == nil {
+		log.Panic("nil a")
+	}
+	return a.f
+}
+
+// nilable(a)
+func testT(t *testing.T, a *A) int {
+	if a == nil {
+		t.Fatal("nil a")
+	}
+	return a.f
+}
+
+// nilable(a, b, c)
+func testTB(tb testing.TB, a, b, c *A) int {
+	if a == nil {
+		tb.Fatalf("nil a")
+	}
+	if b == nil {
+		tb.SkipNow()
+	}
+	if c == nil {
+		tb.FailNow()
+	}
+	return a.f + b.f + c.f
+}
+
+// nilable(a)
+func testNotAlwaysExiting(a *A) int {
+	if a == nil {
+		exitIf(false)
+	}
+	return a.f //want "accessed field `f`"
+}
+
+// nilable(a)
+func testPanicHelper(a *A) int {
+	if a == nil {
+		mustNotHappen("nil a")
+	}
+	return a.f
+}
+
+// nilable(a, b)
+func testExitHelper(a, b *A) int {
+	if a == nil {
+		exitWithContext("nil a")
+	}
+	if b == nil {
+		log.Fatal("nil b")
+	}
+	return a.f + b.f
+}
+
+// nilable(a)
+func testFatalHelper(tb testing.TB, a *A) int {
+	if a == nil {
+		failWithContext(tb, "nil a")
+	}
+	// The helper calling `tb.Fatal` is not inferred to never return.
+	return a.f //want "accessed field `f`"
+}
+
+// nilable(a, b, c)
+func testUpstreamHelpers(tb testing.TB, a, b, c *A) int {
+	if a == nil {
+		fatal.Exit("nil a")
+	}
+	if b == nil {
+		new(fatal.Reporter).Report("nil b")
+	}
+	if c == nil {
+		fatal.Fail(tb, "nil c")
+	}
+	return a.f + b.f + c.f //want "accessed field `f`"
+}
+
+func exitIf(cond bool) {
+	if cond {
+		os.Exit(1)
+	}
+}
+
+func mustNotHappen(msg string) {
+	panic(msg)
+}
+
+// exitWithContext never returns since the function it calls never returns either.
+func exitWithContext(msg string) {
+	exit("context: " + msg)
+}
+
+func exit(msg string) {
+	log.Print(msg)
+	os.Exit(1)
+}
+
+// failWithContext never returns since the function it calls never returns either.
+func failWithContext(tb testing.TB, msg string) {
+	fail(tb, "context: "+msg)
+}
+
+func fail(tb testing.TB, msg string) {
+	tb.Helper()
+	tb.Fatal(msg)
+}
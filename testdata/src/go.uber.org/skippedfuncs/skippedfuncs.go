@@ -0,0 +1,61 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This package tests that the functions exceeding the configured size limits (here 8 CFG blocks)
+// are skipped and reported.
+package skippedfuncs
+
+var dummy bool
+
+func retNil() *int {
+	return nil
+}
+
+func small() int {
+	p := retNil()
+	return *p //want "dereferenced"
+}
+
+func large() int { //want "Skipped analyzing `large` \\(\\d+ bytes, \\d+ CFG blocks\\)"
+	p := retNil()
+	for i := 0; i < 10; i++ {
+		if dummy {
+			continue
+		}
+		switch i {
+		case 1:
+			print(1)
+		case 2:
+			print(2)
+		}
+	}
+	// Not reported since the function is not analyzed.
+	return *p
+}
+
+type T struct{}
+
+func (T) largeMethod() { //want "Skipped analyzing `T.largeMethod`"
+	for i := 0; i < 10; i++ {
+		if dummy {
+			continue
+		}
+		switch i {
+		case 1:
+			print(1)
+		case 2:
+			print(2)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This package tests the suggested fixes attached to the diagnostics, see the golden file for
+// the expected results of applying each of them.
+package suggestedfix
+
+import "errors"
+
+type T struct {
+	f int
+}
+
+func localDeref() int {
+	var x *int
+	return *x //want "dereferenced"
+}
+
+func mapRead(m map[string]*T) (T, int, error) {
+	if len(m) == 0 {
+		return T{}, 0, errors.New("empty")
+	}
+	return T{}, m["a"].f, nil //want "accessed field `f`"
+}
+
+func retNil() *T {
+	return nil
+}
+
+func nilableResult() int {
+	return retNil().f //want "accessed field `f`"
+}
+
+func retNilLocal() *T {
+	return nil
+}
+
+// Both the nil guard and the annotation on `retNilLocal` are applicable here, but only the nil
+// guard is suggested since applying both would be contradictory.
+func nilableLocal() {
+	x := retNilLocal()
+	for i := 0; i < 10; i++ {
+		print(x.f + i) //want "accessed field `f`"
+	}
+}
+
+func noGuardInLoopCond(s []*T) {
+	var x *T
+	for i := 0; i < x.f; i++ { //want "accessed field `f`"
+		print(s[i])
+	}
+}
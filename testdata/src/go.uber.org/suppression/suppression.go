@@ -0,0 +1,81 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This package tests that `//nilaway:ignore` directives suppress the diagnostics on the
+// dereference line and the nil source line, and that unused directives are reported.
+package suppression
+
+var dummy bool
+
+func retNil1() *int {
+	return nil
+}
+
+func retNil2() *int {
+	return nil
+}
+
+func retNil3() *int {
+	return nil
+}
+
+func retNil4() *int {
+	return nil
+}
+
+func retNil5() *int {
+	return nil
+}
+
+func retNilSuppressed() *int {
+	return nil //nilaway:ignore the callers never dereference the result
+}
+
+func derefLine() {
+	print(*retNil1()) //want "dereferenced"
+	print(*retNil2()) //nilaway:ignore
+	//nilaway:ignore guarded by the caller
+	print(*retNil3())
+	print(*retNil4()) //nilaway:ignored // want "dereferenced"
+}
+
+func multipleDirectives() {
+	// Both directives apply to the dereference line, hence both of them are used.
+	//nilaway:ignore guarded by the caller
+	print(*retNil5()) //nilaway:ignore
+}
+
+func sourceLine() {
+	print(*retNilSuppressed())
+	x := retNilSuppressed()
+	print(*x)
+}
+
+func localSource() {
+	var x *int
+	if dummy {
+		x = nil //nilaway:ignore
+	} else {
+		x = new(int)
+	}
+	print(*x)
+}
+
+func unused() {
+	x := new(int)
+	print(*x) //nilaway:ignore // want "Unused //nilaway:ignore directive"
+
+	//nilaway:ignore // want "Unused //nilaway:ignore directive"
+	print(*x)
+}
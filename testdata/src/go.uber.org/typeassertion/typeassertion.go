@@ -0,0 +1,159 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+These tests check the nilability of type assertions: the single-value form panics if the asserted
+value is nil, the value of the comma-ok form is nil unless `ok` is checked (including in
+short-circuiting expressions), and the symbolic variable of a type switch is nil in the `case nil`
+clause. Otherwise, the values are only nil if the asserted value holds a nil pointer, since the
+assertions never succeed on nil interfaces.
+
+<nilaway no inference>
+*/
+package typeassertion
+
+type I interface {
+	M()
+}
+
+type T struct {
+	f int
+}
+
+func (*T) M() {}
+
+// nilable(i)
+func singleValue(i I, j I) int {
+	switch 0 {
+	case 1:
+		return i.(*T).f //want "type-asserted"
+	case 2:
+		v := i.(*T) //want "type-asserted"
+		return v.f
+	default:
+		return j.(*T).f
+	}
+}
+
+// nilable(p)
+func commaOk(j I, p *T) int {
+	switch 0 {
+	case 1:
+		if v, ok := j.(*T); ok {
+			return v.f
+		}
+	case 2:
+		v, ok := j.(*T)
+		if !ok {
+			return 0
+		}
+		return v.f
+	case 3:
+		v, _ := j.(*T)
+		return v.f //want "possibly failed type assertion"
+	case 4:
+		v, ok := j.(*T)
+		_ = ok
+		return v.f //want "possibly failed type assertion"
+	case 5:
+		// the assertion succeeds, but `v` is the nil pointer held by `i`
+		var i I = p
+		if v, ok := i.(*T); ok {
+			return v.f //want "accessed field `f`"
+		}
+	case 6:
+		var i I = &T{}
+		if v, ok := i.(*T); ok {
+			return v.f
+		}
+	case 7:
+		// the assertion never succeeds on a nil interface
+		var i I
+		if v, ok := i.(*T); ok {
+			return v.f
+		}
+	case 8:
+		var i I = nil
+		if v, ok := i.(*T); ok {
+			return v.f
+		}
+	}
+	return 0
+}
+
+// nilable(p)
+func commaOkShortCircuit(j I, p *T) bool {
+	switch 0 {
+	case 1:
+		v, ok := j.(*T)
+		return ok && v.f == 1
+	case 2:
+		v, ok := j.(*T)
+		return !ok || v.f == 1
+	case 3:
+		v, ok := j.(*T)
+		b := ok && v.f == 1
+		return b
+	case 4:
+		v, ok := j.(*T)
+		return ok || v.f == 1 //want "possibly failed type assertion"
+	case 5:
+		var i I = p
+		v, ok := i.(*T)
+		return ok && v.f == 1 //want "accessed field `f`"
+	}
+	return false
+}
+
+// nilable(i)
+func commaOkRefl(i I) {
+	if _, ok := i.(*T); ok {
+		// `i` cannot be nil if the assertion succeeds
+		i.M()
+	}
+	i.M() //want "called"
+}
+
+// nilable(i, p)
+func typeSwitch(i I, j I, p *T) int {
+	switch v := i.(type) {
+	case nil:
+		v.M() //want "literal `nil`"
+	case *T:
+		// a nil `i` never matches `case *T`
+		return v.f
+	}
+
+	var l I = p
+	switch v := l.(type) {
+	case *T:
+		// `v` is the nil pointer held by `l`
+		return v.f //want "accessed field `f`"
+	}
+
+	switch v := j.(type) {
+	case nil, *T:
+		v.M()
+	case I:
+		v.M()
+	}
+
+	// the case types never match a nil interface
+	var k I
+	switch v := k.(type) {
+	case *T:
+		return v.f
+	}
+	return 0
+}
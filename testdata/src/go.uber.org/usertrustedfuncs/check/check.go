@@ -0,0 +1,26 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package check provides a checker whose methods are declared as trusted functions in the config
+// file of the test.
+package check
+
+// Checker checks conditions.
+type Checker struct{}
+
+// NoErr fails if the error is not nil.
+func (*Checker) NoErr(err error) {}
+
+// NotNil fails with the message if the value is nil.
+func (*Checker) NotNil(msg string, v any) {}
@@ -0,0 +1,50 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package must provides assertion helpers and constructors that are declared as trusted functions
+// in the config file of the test.
+package must
+
+// NotNil panics if the value is nil.
+func NotNil(v any) {
+	if v == nil {
+		panic("nil")
+	}
+}
+
+// NotNilf panics with the formatted message if the value is nil.
+func NotNilf(v any, format string, args ...any) {}
+
+// True panics if the value is false.
+func True(b bool) {}
+
+// Fail reports the failure with the message and never returns, which is declared in the config
+// file since the failure is reported by a hook unknown to the analysis.
+func Fail(msg string) {}
+
+// Value is a value returned by the constructors.
+type Value struct {
+	N int
+}
+
+// NewValue returns a new value, which is never nil.
+func NewValue() *Value {
+	var v *Value
+	return v
+}
+
+// Lookup returns a value, which is declared as nilable in the config file.
+func Lookup() *Value {
+	return &Value{}
+}
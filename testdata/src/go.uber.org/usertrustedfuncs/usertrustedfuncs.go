@@ -0,0 +1,94 @@
+//  Copyright (c) 2023 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package usertrustedfuncs tests the trusted functions declared by the users in the config file
+// (see nilaway.yaml), where the functions in `must` and the methods of `check.Checker` are
+// declared with different effects.
+package usertrustedfuncs
+
+import (
+	"go.uber.org/usertrustedfuncs/check"
+	"go.uber.org/usertrustedfuncs/must"
+)
+
+var dummy bool
+
+func retNil() *int {
+	return nil
+}
+
+func retErr() (*int, error) {
+	if dummy {
+		return nil, &myErr{}
+	}
+	return new(int), nil
+}
+
+type myErr struct{}
+
+func (*myErr) Error() string { return "" }
+
+func testArgNonnil() int {
+	x := retNil()
+	must.NotNil(x)
+	return *x
+}
+
+func testArgNonnilf() int {
+	x := retNil()
+	must.NotNilf(x, "x must not be nil: %d", 0)
+	return *x
+}
+
+func testArgNonnilMissing() int {
+	x := retNil()
+	return *x //want "dereferenced"
+}
+
+func testArgTrue() int {
+	x := retNil()
+	must.True(x != nil)
+	return *x
+}
+
+func testNoReturn() int {
+	var x *int
+	if dummy {
+		x = new(int)
+	}
+	if x == nil {
+		must.Fail("x must not be nil")
+	}
+	return *x
+}
+
+func testResultNonnil() int {
+	return must.NewValue().N
+}
+
+func testResultNilable() int {
+	return must.Lookup().N //want "accessed field `N`"
+}
+
+func testArgNil(c *check.Checker) int {
+	x, err := retErr()
+	c.NoErr(err)
+	return *x
+}
+
+func testArgNonnilMethod(c *check.Checker) int {
+	x := retNil()
+	c.NotNil("x must not be nil", x)
+	return *x
+}
@@ -36,6 +36,9 @@ var BoolType = types.Universe.Lookup("bool").Type()
 // BuiltinLen is the builtin "len" function object.
 var BuiltinLen = types.Universe.Lookup("len")
 
+// BuiltinClose is the builtin "close" function object.
+var BuiltinClose = types.Universe.Lookup("close")
+
 // TypeIsDeep checks if a type is an expression that directly admits a deep nilability annotation - deep
 // nilability annotations on all other types are ignored
 func TypeIsDeep(t types.Type) bool {
@@ -195,6 +198,33 @@ func FuncIdentFromCallExpr(expr *ast.CallExpr) *ast.Ident {
 	}
 }
 
+// FuncLitFromAssignment returns the function literal assigned to the ident if the declaration of
+// the ident is an assignment statement (e.g., `f := func() {...}`), nil otherwise
+// nilable(result 0)
+func FuncLitFromAssignment(ident *ast.Ident) *ast.FuncLit {
+	if ident.Obj == nil || ident.Obj.Decl == nil {
+		return nil
+	}
+
+	if assign, ok := ident.Obj.Decl.(*ast.AssignStmt); ok {
+		// TODO get the correct ident for many to one assignments
+		if len(assign.Lhs) != len(assign.Rhs) {
+			return nil
+		}
+
+		for i := range assign.Lhs {
+			if assign.Lhs[i].(*ast.Ident).Obj != ident.Obj {
+				continue
+			}
+			if rhs, ok := assign.Rhs[i].(*ast.FuncLit); ok {
+				return rhs
+			}
+		}
+	}
+
+	return nil
+}
+
 // PartiallyQualifiedFuncName returns the name of the passed function, with the name of its receiver
 // if defined
 func PartiallyQualifiedFuncName(f *types.Func) string {